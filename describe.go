@@ -0,0 +1,153 @@
+package structfill
+
+import (
+	"database/sql"
+	"reflect"
+)
+
+// FieldInfo describes how Fill fills one struct field.
+type FieldInfo struct {
+	// Path is the dotted path of the field as used in errors and by
+	// FillTracked, e.g. "Address.City". Promoted fields of embedded structs
+	// keep the path of the embedding struct.
+	Path string
+	// Key is the input map key the field is read from by Fill, within the map
+	// of its enclosing struct. It is empty for fields not filled from a key of
+	// their own, such as a `fill:",remaining"` map.
+	Key string
+	// Type is the Go type of the field.
+	Type reflect.Type
+	// Default is the field's default tag, or "" if it has none.
+	Default string
+	// Rules are the field's validate rules in tag order, e.g. "required" and
+	// "min=18", with element rules following a "dive" entry.
+	Rules []string
+}
+
+// DescribeStruct returns the metadata Fill uses for every field of structType,
+// a struct or a pointer to one, in the order Fill visits them: a nested
+// struct is followed by its own fields, and the fields of embedded structs
+// appear at the position of the embedded field. Fields tagged `fill:"-"`,
+// unexported fields and embedded interfaces are left out, as are promoted
+// fields shadowed by a field with the same key in an embedding struct, which
+// Fill never reads from that key.
+func DescribeStruct(structType any) ([]FieldInfo, error) {
+	t := reflect.TypeOf(structType)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, ErrNotStructPointer
+	}
+	f := &filler{}
+	return f.describe(t, "", nil, map[reflect.Type]bool{t: true}, nil)
+}
+
+// describe appends the descriptions of the fields of struct type t to infos.
+// visiting holds the struct types being described, so self-referential types
+// are only described once, and shadowed holds the keys of the fields of the
+// structs embedding t.
+func (f *filler) describe(t reflect.Type, path string, infos []FieldInfo, visiting map[reflect.Type]bool, shadowed map[string]bool) ([]FieldInfo, error) {
+	fields := cachedFields(t)
+	var embeddedShadowed map[string]bool
+	shadowedKnown := false
+	for i := range fields {
+		info := &fields[i]
+		if info.skip || info.embeddedInterface {
+			continue
+		}
+		fieldType := info.field.Type
+		if info.embedded {
+			if !shadowedKnown {
+				embeddedShadowed = f.shadowedKeys(fields, &structState{shadowed: shadowed})
+				shadowedKnown = true
+			}
+			var err error
+			if infos, err = f.describeNested(fieldType, path, infos, visiting, embeddedShadowed); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		key, hasKey := f.fieldKey(info)
+		if hasKey && shadowed[key] {
+			continue
+		}
+
+		fieldPath := joinPath(path, info.field.Name)
+		if info.rulesErr != nil {
+			return nil, wrapFieldError(fieldPath, info.rulesErr)
+		}
+		described := FieldInfo{
+			Path:    fieldPath,
+			Type:    fieldType,
+			Default: info.field.Tag.Get("default"),
+			Rules:   describeRules(info),
+		}
+		if hasKey && !info.remaining {
+			described.Key = key
+		}
+		infos = append(infos, described)
+
+		if isNestedStruct(fieldType) {
+			var err error
+			if infos, err = f.describeNested(fieldType, fieldPath, infos, visiting, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return infos, nil
+}
+
+// describeNested describes the fields of the struct or struct pointer type t
+// unless it is already being described further up.
+func (f *filler) describeNested(t reflect.Type, path string, infos []FieldInfo, visiting map[reflect.Type]bool, shadowed map[string]bool) ([]FieldInfo, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if visiting[t] {
+		return infos, nil
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+	return f.describe(t, path, infos, visiting, shadowed)
+}
+
+// isNestedStruct reports whether Fill fills fields of type t from a nested
+// map, field by field, rather than through a type-specific handler.
+func isNestedStruct(t reflect.Type) bool {
+	if !isStructOrStructPtr(t) {
+		return false
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return false
+	}
+	ptr := reflect.PointerTo(t)
+	return !ptr.Implements(textUnmarshalerType) &&
+		!ptr.Implements(reflect.TypeOf((*sql.Scanner)(nil)).Elem()) &&
+		!ptr.Implements(reflect.TypeOf((*Fillable)(nil)).Elem())
+}
+
+// describeRules formats the parsed validate rules of a field.
+func describeRules(info *fieldInfo) []string {
+	var rules []string
+	for _, r := range info.rules {
+		rules = append(rules, formatRule(r))
+	}
+	if info.elemRules != nil {
+		rules = append(rules, "dive")
+		for _, r := range info.elemRules {
+			rules = append(rules, formatRule(r))
+		}
+	}
+	return rules
+}
+
+func formatRule(r rule) string {
+	if r.value == "" {
+		return r.name
+	}
+	return r.name + "=" + r.value
+}
@@ -0,0 +1,69 @@
+package structfill
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ServiceSpec struct {
+	Pet
+	Port     int            `fill:"listen_port" default:"8080" validate:"min=1,max=65535"`
+	Started  time.Time      `default:"2020-01-01T00:00:00Z"`
+	Owner    *Address       `validate:"required"`
+	Ports    []int          `validate:"min=1,dive,max=65535"`
+	Internal string         `fill:"-"`
+	Extra    map[string]any `fill:",remaining"`
+	Tree     TreeNode
+	secret   string
+}
+
+func TestDescribeStruct(t *testing.T) {
+	infos, err := DescribeStruct(&ServiceSpec{})
+	assert.NoError(t, err)
+	assert.Equal(t, []FieldInfo{
+		{Path: "Name", Key: "name", Type: reflect.TypeOf("")},
+		{Path: "Port", Key: "listen_port", Type: reflect.TypeOf(0), Default: "8080", Rules: []string{"min=1", "max=65535"}},
+		{Path: "Started", Key: "started", Type: reflect.TypeOf(time.Time{}), Default: "2020-01-01T00:00:00Z"},
+		{Path: "Owner", Key: "owner", Type: reflect.TypeOf(&Address{}), Rules: []string{"required"}},
+		{Path: "Owner.Street", Key: "street", Type: reflect.TypeOf(""), Default: "Main St"},
+		{Path: "Owner.City", Key: "city", Type: reflect.TypeOf("")},
+		{Path: "Owner.Height", Key: "height", Type: reflect.TypeOf(0.0), Default: "1.8", Rules: []string{"min=1.5", "max=2.0"}},
+		{Path: "Ports", Key: "ports", Type: reflect.TypeOf([]int{}), Rules: []string{"min=1", "dive", "max=65535"}},
+		{Path: "Extra", Type: reflect.TypeOf(map[string]any{})},
+		{Path: "Tree", Key: "tree", Type: reflect.TypeOf(TreeNode{})},
+		{Path: "Tree.Value", Key: "value", Type: reflect.TypeOf(0)},
+		{Path: "Tree.Children", Key: "children", Type: reflect.TypeOf([]TreeNode{})},
+		// Self-referential types are only expanded once
+		{Path: "Tree.Parent", Key: "parent", Type: reflect.TypeOf(&TreeNode{})},
+	}, infos)
+}
+
+func TestDescribeStruct_ShadowedFields(t *testing.T) {
+	infos, err := DescribeStruct(Derived{})
+	assert.NoError(t, err)
+	// Base's Name and Label are shadowed by Derived's Name and Title
+	assert.Equal(t, []FieldInfo{
+		{Path: "ID", Key: "id", Type: reflect.TypeOf(0)},
+		{Path: "Name", Key: "name", Type: reflect.TypeOf("")},
+		{Path: "Title", Key: "label", Type: reflect.TypeOf("")},
+	}, infos)
+}
+
+func TestDescribeStruct_NotStruct(t *testing.T) {
+	_, err := DescribeStruct(map[string]any{})
+	assert.ErrorIs(t, err, ErrNotStructPointer)
+
+	_, err = DescribeStruct(nil)
+	assert.ErrorIs(t, err, ErrNotStructPointer)
+}
+
+func TestDescribeStruct_InvalidRules(t *testing.T) {
+	_, err := DescribeStruct(struct {
+		Name string `validate:"=3"`
+	}{})
+	assert.Error(t, err)
+	assert.Equal(t, "Name: invalid validate tag format", err.Error())
+}
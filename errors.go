@@ -0,0 +1,106 @@
+package structfill
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotStructPointer is returned when the fill target is not a pointer
+	// to a struct.
+	ErrNotStructPointer = errors.New("provided type must be a pointer to a struct")
+
+	// ErrUnsupportedType is returned, wrapped, when a field's type cannot be
+	// filled.
+	ErrUnsupportedType = errors.New("unsupported type")
+
+	// ErrValidation matches, via errors.Is, every error produced by a failed
+	// validate rule.
+	ErrValidation = errors.New("validation failed")
+
+	// ErrMaxDepth is returned, wrapped, when nested structs in the input go
+	// deeper than Options.MaxDepth.
+	ErrMaxDepth = errors.New("maximum nesting depth exceeded")
+)
+
+// FieldError is returned when filling a specific field fails. It records the
+// dotted path of the field, including slice indices (e.g. "Prop2.Prop4[1].Prop5"),
+// alongside the underlying error.
+type FieldError struct {
+	path string
+	err  error
+}
+
+func (e *FieldError) Error() string {
+	return e.path + ": " + e.err.Error()
+}
+
+// Path returns the dotted path of the field that failed.
+func (e *FieldError) Path() string {
+	return e.path
+}
+
+// Err returns the underlying error.
+func (e *FieldError) Err() error {
+	return e.err
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.err
+}
+
+// wrapFieldError attaches path to err, unless err already carries the path of
+// a more deeply nested field or path is empty because err concerns the
+// top-level struct itself.
+func wrapFieldError(path string, err error) error {
+	var fieldErr *FieldError
+	if path == "" || errors.As(err, &fieldErr) {
+		return err
+	}
+	return &FieldError{path: path, err: err}
+}
+
+// ValidationError is returned, wrapped in a FieldError, when a value fails a
+// validate rule. It matches ErrValidation via errors.Is.
+type ValidationError struct {
+	// Field is the name of the struct field that failed, e.g. "Age". For
+	// element rules following dive it is the name of the slice field.
+	Field string
+	// Rule is the name of the rule that failed, e.g. "min" or "oneof".
+	Rule string
+	// Limit is the rule's value as written in the tag, e.g. "18" for min=18.
+	// It is empty for flag rules such as required.
+	Limit string
+	// Value is the value that failed the rule, as an int64, uint64, float64,
+	// string or bool depending on the field's kind. For min/max/len rules
+	// on slices it is the slice length.
+	Value any
+
+	err error
+}
+
+func (e *ValidationError) Error() string {
+	return e.err.Error()
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.err
+}
+
+func validationErrorf(r rule, value any, format string, args ...any) error {
+	return &ValidationError{Rule: r.name, Limit: r.value, Value: value, err: fmt.Errorf(format, args...)}
+}
+
+// setValidationField records fieldName on a ValidationError from a field's
+// own rules, leaving errors from more deeply nested fields as they are.
+func setValidationField(err error, fieldName string) error {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) && validationErr.Field == "" {
+		validationErr.Field = fieldName
+	}
+	return err
+}
@@ -0,0 +1,14 @@
+package structfill
+
+import "encoding/json"
+
+// FillFromJSON decodes data as a JSON object and fills structType from it.
+// JSON numbers decode as float64 and are converted for integer fields as
+// described on Options.DisallowTruncation.
+func FillFromJSON(structType any, data []byte, _typeRegistry ...map[string]func() any) error {
+	var inputMap map[string]any
+	if err := json.Unmarshal(data, &inputMap); err != nil {
+		return err
+	}
+	return Fill(structType, inputMap, _typeRegistry...)
+}
@@ -0,0 +1,120 @@
+package structfill
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Inventory struct {
+	Owner     Employee
+	Count     int
+	Stock     uint32
+	Weight    float64
+	Items     []int
+	Locations []Classroom
+}
+
+func TestFillFromJSON(t *testing.T) {
+	var inventory Inventory
+	data := []byte(`{
+		"owner": {"name": "Alice", "age": 29, "address": {"city": "Springfield", "height": 1.75}},
+		"count": 1000000,
+		"stock": 42,
+		"weight": 12.5,
+		"items": [1, 2, 3],
+		"locations": [{"building": "A", "number": 101}]
+	}`)
+
+	err := FillFromJSON(&inventory, data)
+	assert.NoError(t, err)
+	assert.Equal(t, Inventory{
+		Owner:     Employee{Name: "Alice", Age: 29, Address: Address{Street: "Main St", City: "Springfield", Height: 1.75}},
+		Count:     1000000,
+		Stock:     42,
+		Weight:    12.5,
+		Items:     []int{1, 2, 3},
+		Locations: []Classroom{{Building: "A", Number: 101}},
+	}, inventory)
+}
+
+func TestFillFromJSON_DurationNanoseconds(t *testing.T) {
+	var timeouts Timeouts
+
+	err := FillFromJSON(&timeouts, []byte(`{"read": 1000000000, "write": 1e9}`))
+	assert.NoError(t, err)
+	assert.Equal(t, Timeouts{Read: time.Second, Write: time.Second}, timeouts)
+}
+
+func TestFillFromJSON_InvalidJSON(t *testing.T) {
+	var inventory Inventory
+
+	err := FillFromJSON(&inventory, []byte(`{"count":`))
+	assert.Error(t, err)
+}
+
+func TestFillFromJSON_Nulls(t *testing.T) {
+	var inventory Inventory
+	data := []byte(`{
+		"owner": {"name": null, "age": null, "address": null},
+		"count": null,
+		"stock": null,
+		"weight": null,
+		"items": [1, null, 3],
+		"locations": null
+	}`)
+
+	err := FillFromJSON(&inventory, data)
+	assert.NoError(t, err)
+	assert.Equal(t, Inventory{
+		Owner: Employee{Name: "John Doe", Age: 30, Address: Address{Street: "Main St", Height: 1.8}},
+		Items: []int{1, 0, 3},
+	}, inventory)
+}
+
+func TestFill_JSONNumbers(t *testing.T) {
+	var inventory Inventory
+	inputMap := map[string]any{
+		"count":  json.Number("9007199254740993"),
+		"stock":  json.Number("42"),
+		"weight": json.Number("12.5"),
+		"items":  []any{json.Number("1"), json.Number("2")},
+	}
+
+	err := Fill(&inventory, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, 9007199254740993, inventory.Count)
+	assert.Equal(t, uint32(42), inventory.Stock)
+	assert.Equal(t, 12.5, inventory.Weight)
+	assert.Equal(t, []int{1, 2}, inventory.Items)
+
+	err = Fill(&inventory, map[string]any{"count": json.Number("2.9")})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, inventory.Count)
+
+	err = Fill(&inventory, map[string]any{"stock": json.Number("1e3")})
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1000), inventory.Stock)
+}
+
+func TestFill_JSONNumbersRejectedForStringsAndBools(t *testing.T) {
+	var person Employee
+	err := Fill(&person, map[string]any{"name": json.Number("1")})
+	assert.Error(t, err)
+	assert.Equal(t, "Name: invalid type for field Name, expected string but got json.Number", err.Error())
+
+	var toggles Toggles
+	err = Fill(&toggles, map[string]any{"active": json.Number("1")})
+	assert.Error(t, err)
+	assert.Equal(t, "Active: invalid type for field Active, expected bool but got json.Number", err.Error())
+}
+
+func TestFillMap_JSONNumbers(t *testing.T) {
+	var counts map[string]int64
+
+	err := FillMap(&counts, map[string]any{"a": json.Number("9007199254740993")})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"a": 9007199254740993}, counts)
+}
@@ -0,0 +1,208 @@
+package structfill
+
+import "reflect"
+
+// Converter converts a raw input value into a value of the target type.
+type Converter func(input any, target reflect.Type) (any, error)
+
+// TypeResolver returns a new instance, typically a pointer to a struct, of the
+// concrete type to fill from an interface element's input map.
+type TypeResolver func(elem map[string]any) (any, error)
+
+// FillFunc fills the field pointed to by fieldPtr from its raw input value,
+// replacing all built-in handling for the field's type.
+type FillFunc func(fieldPtr any, input any) error
+
+// ValidationFunc checks a value against a custom validate rule registered in
+// Options.Validators. param is the text after "=" in the tag, or "" for flag
+// rules such as `validate:"positive"`. value is an int64, uint64, float64,
+// string or bool depending on the field's kind.
+type ValidationFunc func(value any, param string) error
+
+// KeyMatching controls how struct fields are matched against input map keys.
+// A fill tag (e.g. `fill:"user_id"`) always takes precedence; the strategy
+// only decides what happens for fields without one.
+type KeyMatching int
+
+const (
+	// MatchLowercase matches the lowercased field name, so a field UserID is
+	// looked up as "userid". This is the strategy used by Fill.
+	MatchLowercase KeyMatching = iota
+	// MatchExact matches the field name exactly as declared, e.g. "UserID".
+	MatchExact
+	// MatchTag only fills fields that have a fill tag.
+	MatchTag
+	// MatchCaseInsensitive matches field names, and fill or json tag names,
+	// against input keys ignoring case on both sides, so a field UserID is
+	// filled from "UserID", "userId" or "userid". Input keys that only
+	// differ by case, such as "Name" and "name", are an error. The other
+	// strategies look up a single spelling, so such keys are simply distinct.
+	MatchCaseInsensitive
+)
+
+// Options configures FillWithOptions. The zero value behaves like Fill
+// without a type registry.
+type Options struct {
+	// KeyMatching selects how field names are matched to input map keys.
+	KeyMatching KeyMatching
+
+	// KeyFunc derives the input key from a field name, e.g. to map UserID to
+	// "user_id". It replaces the KeyMatching derivation for fields without a
+	// fill or json tag name; with MatchCaseInsensitive its result is still
+	// matched ignoring case.
+	KeyFunc func(fieldName string) string
+
+	// UseJSONTags looks up fields by the name in their json tag (ignoring
+	// options such as omitempty) when they have no fill tag. Fields tagged
+	// `json:"-"` are not filled.
+	UseJSONTags bool
+
+	// Strict makes the fill fail if the input map, or any nested map, contains
+	// keys that do not correspond to a settable field.
+	Strict bool
+
+	// ExpandDottedKeys treats input keys containing dots as paths into nested
+	// structs, so {"address.city": "x"} fills like {"address": {"city": "x"}}.
+	// Dotted keys are merged into a nested map given under the same key, and
+	// win over the same key inside it.
+	ExpandDottedKeys bool
+
+	// DisallowTruncation makes float inputs with a fractional part an error
+	// for integer fields instead of truncating them towards zero.
+	DisallowTruncation bool
+
+	// LenientBools accepts yes/no, on/off and enabled/disabled (in any case)
+	// for bool fields, in addition to the forms understood by strconv.ParseBool.
+	LenientBools bool
+
+	// LenientInts accepts digit separators ("1_000") and exponent notation
+	// ("1e3") in string inputs for integer fields. Numbers with an exponent
+	// are truncated like float inputs, see DisallowTruncation.
+	LenientInts bool
+
+	// TrimSpace removes leading and trailing whitespace from string inputs
+	// for string fields before they are validated and assigned. A single
+	// field can opt in with `fill:",trim"`.
+	TrimSpace bool
+
+	// CoerceBools fills integer fields from bool inputs, true as 1 and false
+	// as 0. Bool fields accept the numbers 0 and 1 (and "0" and "1") with or
+	// without this option; any other number, such as 2, is an error.
+	//
+	//	input         int field   bool field
+	//	true/false    1/0 (*)     true/false
+	//	0/1, 0.0/1.0  0/1         false/true
+	//	other number  number      error
+	//
+	// (*) only with CoerceBools; otherwise an error.
+	CoerceBools bool
+
+	// StrictTypes requires inputs for string, bool and numeric fields to
+	// already have a matching Go type instead of being coerced, so "42" for
+	// an int field or true for a string field is an error. Numeric fields
+	// accept any Go number or json.Number, so maps decoded from JSON still
+	// fill, but integer fields reject numbers with a fractional part as with
+	// DisallowTruncation. It takes precedence over CoerceBools and
+	// LenientBools.
+	StrictTypes bool
+
+	// TruncateArrays drops input elements that do not fit in a fixed-size
+	// array field instead of returning an error.
+	TruncateArrays bool
+
+	// WrapScalars accepts a single non-slice value for a slice field and
+	// fills the field with a one-element slice, so "a" fills a []string as
+	// []string{"a"}.
+	WrapScalars bool
+
+	// AppendSlices appends filled elements to a slice field's existing
+	// elements instead of replacing them.
+	AppendSlices bool
+
+	// TypeRegistry maps type identifiers to constructors used to instantiate
+	// the elements of interface slices.
+	TypeRegistry map[string]func() any
+
+	// TypeResolvers maps interface types to functions that choose the
+	// concrete type of an interface field or slice element from its whole
+	// input map, for types that depend on more than a single discriminator
+	// key. A resolver returns a pointer to a new struct, which is then filled
+	// from the same map; it takes the place of TypeRegistry for its type.
+	TypeResolvers map[reflect.Type]TypeResolver
+
+	// DisallowUnknownTypes makes an interface slice element whose type
+	// identifier is not in TypeRegistry an error. By default such elements
+	// are skipped with a logged warning.
+	DisallowUnknownTypes bool
+
+	// Logger receives warnings such as skipped interface slice elements. It
+	// defaults to log.Printf; use a no-op function to silence warnings.
+	Logger func(format string, args ...any)
+
+	// SkipUnsupported leaves fields whose type can't be filled, such as
+	// channels and funcs, at their zero value when the input has a key for
+	// them, logging a warning through Logger instead of failing the fill
+	// with ErrUnsupportedType.
+	SkipUnsupported bool
+
+	// ReportUnexported logs a warning through Logger for each input key that
+	// matches an unexported field. Such fields can't be set, so the key is
+	// otherwise ignored without notice.
+	ReportUnexported bool
+
+	// Discriminator is the input key holding the type identifier of interface
+	// elements. It defaults to "type" and can be overridden per field with a
+	// fill tag option, e.g. `fill:"pets,discriminator=kind"`.
+	Discriminator string
+
+	// DisableDefaults leaves fields whose key is missing from the input map
+	// untouched instead of applying their default or env tags or the
+	// Defaults of a Defaulter. This allows layering several partial maps onto
+	// the same struct.
+	DisableDefaults bool
+
+	// DefaultsOnlyIfZero applies default and env tags, and the Defaults of a
+	// Defaulter, only to fields that currently hold their zero value, so
+	// values set by an earlier fill survive a later fill that omits their keys.
+	DefaultsOnlyIfZero bool
+
+	// EmptyContainers sets slice and map fields whose key is missing or null,
+	// and that have no default, to empty non-nil values, so they re-marshal
+	// as [] and {} rather than null. By default such fields are left nil; an
+	// empty input slice or map always fills an empty non-nil one. It is
+	// applied along with defaults, so DisableDefaults turns it off too.
+	EmptyContainers bool
+
+	// DefaultFuncs registers default generators by name for fields tagged
+	// e.g. `defaultfunc:"uuid"`, for defaults such as IDs or timestamps that
+	// can't be written as a static default tag. A generator is called each
+	// time its field needs a default, and its result is converted to the
+	// field's type like a slice element.
+	DefaultFuncs map[string]func() any
+
+	// Converters maps field types to functions that convert input values for
+	// them. A registered converter takes precedence over all built-in
+	// handling for fields of that type.
+	Converters map[reflect.Type]Converter
+
+	// FillFuncs maps field types to functions that fill struct fields of that
+	// type themselves, e.g. a GeoPoint from a [lat, lng] array. Unlike a
+	// Converter, a FillFunc receives a pointer to the field and is called
+	// before any built-in handling, including Converters and validate rules
+	// other than required. Missing or null keys still get their defaults.
+	FillFuncs map[reflect.Type]FillFunc
+
+	// TimeLayouts are tried in order for time.Time fields without a format
+	// tag, instead of just time.RFC3339. A format tag can list several
+	// layouts itself, separated by "|".
+	TimeLayouts []string
+
+	// MaxDepth limits how deeply nested structs are filled, guarding against
+	// deeply nested input for self-referential types. It defaults to 64.
+	MaxDepth int
+
+	// Validators registers custom validate rules by name, e.g. "multipleof"
+	// for `validate:"multipleof=5"`. Built-in rules take precedence; a rule
+	// that is neither built in nor registered is an error.
+	Validators map[string]ValidationFunc
+}
@@ -0,0 +1,1062 @@
+package structfill
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Key matching
+type Identity struct {
+	ID    string
+	Id    string
+	Login string `fill:"user"`
+}
+
+func TestFillWithOptions_MatchLowercaseIsDefault(t *testing.T) {
+	var employee Employee
+	inputMap := map[string]any{"name": "Alice"}
+
+	err := FillWithOptions(&employee, inputMap, Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Alice", employee.Name)
+}
+
+func TestFillWithOptions_MatchExact(t *testing.T) {
+	var identity Identity
+	inputMap := map[string]any{
+		"ID":   "upper",
+		"Id":   "mixed",
+		"id":   "lower",
+		"user": "alice",
+	}
+
+	err := FillWithOptions(&identity, inputMap, Options{KeyMatching: MatchExact})
+	assert.NoError(t, err)
+	assert.Equal(t, Identity{ID: "upper", Id: "mixed", Login: "alice"}, identity)
+}
+
+func TestFillWithOptions_MatchTag(t *testing.T) {
+	var identity Identity
+	inputMap := map[string]any{
+		"id":   "lower",
+		"user": "alice",
+	}
+
+	err := FillWithOptions(&identity, inputMap, Options{KeyMatching: MatchTag})
+	assert.NoError(t, err)
+	assert.Equal(t, Identity{Login: "alice"}, identity)
+}
+
+func TestFillWithOptions_MatchCaseInsensitive(t *testing.T) {
+	var person Employee
+	inputMap := map[string]any{
+		"NAME": "Alice",
+		"Age":  29,
+		"aDDress": map[string]any{
+			"City":   "Springfield",
+			"street": "Elm St",
+		},
+	}
+
+	err := FillWithOptions(&person, inputMap, Options{KeyMatching: MatchCaseInsensitive, Strict: true})
+	assert.NoError(t, err)
+	assert.Equal(t, Employee{Name: "Alice", Age: 29, Address: Address{Street: "Elm St", City: "Springfield", Height: 1.8}}, person)
+}
+
+func TestFillWithOptions_MatchCaseInsensitiveTags(t *testing.T) {
+	var identity Identity
+
+	err := FillWithOptions(&identity, map[string]any{"USER": "alice"}, Options{KeyMatching: MatchCaseInsensitive})
+	assert.NoError(t, err)
+	assert.Equal(t, Identity{Login: "alice"}, identity)
+}
+
+func TestFillWithOptions_MatchCaseInsensitiveAmbiguousKeys(t *testing.T) {
+	var person Employee
+	opts := Options{KeyMatching: MatchCaseInsensitive}
+
+	err := FillWithOptions(&person, map[string]any{"Name": "Alice", "name": "Bob", "NAME": "Carol", "age": 29}, opts)
+	assert.Error(t, err)
+	assert.Equal(t, "ambiguous keys in input map: NAME, Name, name", err.Error())
+
+	err = FillWithOptions(&person, map[string]any{"address": map[string]any{"City": "Springfield", "city": "Shelbyville"}}, opts)
+	assert.Error(t, err)
+	assert.Equal(t, "Address: ambiguous keys in input map: City, city", err.Error())
+
+	// Other strategies only look up one spelling
+	err = FillWithOptions(&person, map[string]any{"Name": "Alice", "name": "Bob"}, Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Bob", person.Name)
+}
+
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 && !unicode.IsUpper(rune(name[i-1])) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+type APIKey struct {
+	KeyID     string
+	OwnerName string
+	ExpiresIn int
+	Scope     string `fill:"permissions"`
+}
+
+func TestFillWithOptions_KeyFunc(t *testing.T) {
+	var key APIKey
+	inputMap := map[string]any{
+		"key_id":      "k1",
+		"owner_name":  "alice",
+		"expires_in":  3600,
+		"permissions": "read",
+	}
+
+	err := FillWithOptions(&key, inputMap, Options{KeyFunc: snakeCase, Strict: true})
+	assert.NoError(t, err)
+	assert.Equal(t, APIKey{KeyID: "k1", OwnerName: "alice", ExpiresIn: 3600, Scope: "read"}, key)
+}
+
+// Strict mode
+func TestFillWithOptions_StrictUnknownKeys(t *testing.T) {
+	var person Employee
+	inputMap := map[string]any{
+		"nam": "Alice",
+		"age": 29,
+		"foo": true,
+	}
+
+	err := FillWithOptions(&person, inputMap, Options{Strict: true})
+	assert.Error(t, err)
+	assert.Equal(t, "unknown keys in input map: foo, nam", err.Error())
+}
+
+func TestFillWithOptions_StrictNestedUnknownKeys(t *testing.T) {
+	var person Employee
+	inputMap := map[string]any{
+		"name": "Alice",
+		"address": map[string]any{
+			"city":   "Springfield",
+			"street": "Main St",
+			"zip":    "12345",
+		},
+	}
+
+	err := FillWithOptions(&person, inputMap, Options{Strict: true})
+	assert.Error(t, err)
+	assert.Equal(t, "Address: unknown keys in input map: zip", err.Error())
+}
+
+func TestFillWithOptions_StrictEmbeddedFields(t *testing.T) {
+	var b B
+	inputMap := map[string]any{
+		"prop1": "value1",
+		"prop2": 2,
+	}
+
+	err := FillWithOptions(&b, inputMap, Options{Strict: true})
+	assert.NoError(t, err)
+	assert.Equal(t, B{A: A{Prop1: "value1"}, Prop2: 2}, b)
+}
+
+func TestFillWithOptions_StrictInterfaceSlice(t *testing.T) {
+	var house House
+	inputMap := map[string]any{
+		"pets": []map[string]any{
+			{"type": "Dog", "name": "Rex"},
+		},
+	}
+	opts := Options{
+		Strict: true,
+		TypeRegistry: map[string]func() any{
+			"Dog": func() any { return &Dog{} },
+		},
+	}
+
+	err := FillWithOptions(&house, inputMap, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, House{Pets: []Animal{&Dog{Pet{Name: "Rex"}}}}, house)
+}
+
+// Truncation
+func TestFillWithOptions_DisallowTruncation(t *testing.T) {
+	var sizes Sizes
+
+	err := FillWithOptions(&sizes, map[string]any{"large": 2.5}, Options{DisallowTruncation: true})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "value 2.5 has a fractional part")
+
+	err = FillWithOptions(&sizes, map[string]any{"large": 2.0}, Options{DisallowTruncation: true})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), sizes.Large)
+}
+
+func TestFillWithOptions_DisallowTruncationElements(t *testing.T) {
+	var school School
+
+	err := FillWithOptions(&school, map[string]any{"ages": []any{1.9}}, Options{DisallowTruncation: true})
+	assert.Error(t, err)
+	assert.Equal(t, "Ages[0]: error converting slice element for field Ages: value 1.9 has a fractional part", err.Error())
+
+	err = FillWithOptions(&school, map[string]any{"ages": []any{1.9}}, Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, school.Ages)
+}
+
+// Discriminator
+func TestFillWithOptions_Discriminator(t *testing.T) {
+	var house House
+	inputMap := map[string]any{
+		"pets": []map[string]any{
+			{"@type": "Cat", "name": "Whiskers"},
+		},
+	}
+	opts := Options{
+		Strict:        true,
+		Discriminator: "@type",
+		TypeRegistry: map[string]func() any{
+			"Cat": func() any { return &Cat{} },
+		},
+	}
+
+	err := FillWithOptions(&house, inputMap, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, House{Pets: []Animal{&Cat{Pet: Pet{Name: "Whiskers"}}}}, house)
+}
+
+// Converters
+type Money int64
+
+type Order struct {
+	Price Money
+	Items int
+}
+
+func parseMoney(input any, target reflect.Type) (any, error) {
+	s, ok := input.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected string, got %T", input)
+	}
+	dollars, err := strconv.ParseFloat(strings.TrimPrefix(s, "$"), 64)
+	if err != nil {
+		return nil, err
+	}
+	return Money(math.Round(dollars * 100)), nil
+}
+
+func TestFillWithOptions_Converters(t *testing.T) {
+	var order Order
+	opts := Options{
+		Converters: map[reflect.Type]Converter{
+			reflect.TypeOf(Money(0)): parseMoney,
+		},
+	}
+
+	err := FillWithOptions(&order, map[string]any{"price": "$12.50", "items": 2}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, Order{Price: 1250, Items: 2}, order)
+
+	err = FillWithOptions(&order, map[string]any{"price": 12}, opts)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Price: expected string, got int")
+}
+
+func ExampleFillWithOptions_converter() {
+	var order Order
+	opts := Options{
+		Converters: map[reflect.Type]Converter{
+			reflect.TypeOf(Money(0)): parseMoney,
+		},
+	}
+
+	if err := FillWithOptions(&order, map[string]any{"price": "$12.50"}, opts); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(order.Price)
+	// Output: 1250
+}
+
+// Fill functions
+type GeoPoint struct {
+	Lat, Lng float64
+}
+
+type Trip struct {
+	From GeoPoint
+	To   GeoPoint `default:"{\"lat\": 1, \"lng\": 2}"`
+}
+
+func fillGeoPoint(fieldPtr any, input any) error {
+	pair, ok := input.([]any)
+	if !ok || len(pair) != 2 {
+		return fmt.Errorf("expected [lat, lng], got %v", input)
+	}
+	lat, latOK := pair[0].(float64)
+	lng, lngOK := pair[1].(float64)
+	if !latOK || !lngOK {
+		return fmt.Errorf("expected numbers, got %v", input)
+	}
+	*fieldPtr.(*GeoPoint) = GeoPoint{Lat: lat, Lng: lng}
+	return nil
+}
+
+func TestFillWithOptions_FillFuncs(t *testing.T) {
+	var trip Trip
+	opts := Options{
+		FillFuncs: map[reflect.Type]FillFunc{
+			reflect.TypeOf(GeoPoint{}): fillGeoPoint,
+		},
+	}
+
+	err := FillWithOptions(&trip, map[string]any{"from": []any{51.5, -0.12}}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, Trip{From: GeoPoint{Lat: 51.5, Lng: -0.12}, To: GeoPoint{Lat: 1, Lng: 2}}, trip)
+
+	err = FillWithOptions(&trip, map[string]any{"from": map[string]any{"lat": 51.5}}, opts)
+	assert.Error(t, err)
+	assert.Equal(t, "From: expected [lat, lng], got map[lat:51.5]", err.Error())
+}
+
+func TestFillWithOptions_StrictWithRemaining(t *testing.T) {
+	var plugin Plugin
+	inputMap := map[string]any{
+		"name": "cache",
+		"size": 64,
+	}
+
+	err := FillWithOptions(&plugin, inputMap, Options{Strict: true})
+	assert.NoError(t, err)
+	assert.Equal(t, Plugin{Name: "cache", Extra: map[string]any{"size": 64}}, plugin)
+}
+
+// Lenient bools
+type Features struct {
+	Cache   bool
+	Logging bool
+	Metrics bool
+	Tracing bool
+}
+
+func TestFillWithOptions_LenientBools(t *testing.T) {
+	var features Features
+	inputMap := map[string]any{
+		"cache":   "Yes",
+		"logging": "off",
+		"metrics": "ENABLED",
+		"tracing": "true",
+	}
+
+	err := FillWithOptions(&features, inputMap, Options{LenientBools: true})
+	assert.NoError(t, err)
+	assert.Equal(t, Features{Cache: true, Logging: false, Metrics: true, Tracing: true}, features)
+
+	err = FillWithOptions(&features, map[string]any{"cache": "maybe"}, Options{LenientBools: true})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid boolean "maybe", accepted values are 1/0, true/false`)
+}
+
+func TestFillWithOptions_StrictBoolsByDefault(t *testing.T) {
+	var features Features
+
+	err := FillWithOptions(&features, map[string]any{"cache": "yes"}, Options{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid boolean "yes", accepted values are 1, t, T, TRUE, true, True`)
+}
+
+// Lenient integers
+func TestFillWithOptions_LenientInts(t *testing.T) {
+	var sizes Sizes
+	opts := Options{LenientInts: true}
+
+	err := FillWithOptions(&sizes, map[string]any{"small": "-1_2", "large": "1_000_000", "count": "1e3"}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, Sizes{Small: -12, Large: 1000000, Count: 1000}, sizes)
+
+	err = FillWithOptions(&sizes, map[string]any{"large": "2.5E1"}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(25), sizes.Large)
+
+	err = FillWithOptions(&sizes, map[string]any{"large": "2.5e0"}, Options{LenientInts: true, DisallowTruncation: true})
+	assert.Error(t, err)
+}
+
+func TestFillWithOptions_LenientIntsInvalid(t *testing.T) {
+	var sizes Sizes
+	opts := Options{LenientInts: true}
+
+	for _, input := range []string{"_1000", "1000_", "1__000", "1_e3", "e3", "1e", "1.5"} {
+		err := FillWithOptions(&sizes, map[string]any{"large": input}, opts)
+		assert.Error(t, err, input)
+	}
+
+	err := FillWithOptions(&sizes, map[string]any{"count": "1e9"}, opts)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "overflows")
+}
+
+func TestFillWithOptions_StrictIntsByDefault(t *testing.T) {
+	var sizes Sizes
+
+	for _, input := range []string{"1_000", "1e3"} {
+		err := FillWithOptions(&sizes, map[string]any{"large": input}, Options{})
+		assert.Error(t, err, input)
+	}
+}
+
+// Layered fills
+func TestFillWithOptions_DisableDefaults(t *testing.T) {
+	var person Employee
+
+	err := Fill(&person, map[string]any{"name": "Alice", "age": 40})
+	assert.NoError(t, err)
+
+	// A second, partial layer must not reset Name and Age to their defaults
+	err = FillWithOptions(&person, map[string]any{"address": map[string]any{"city": "Springfield"}}, Options{DisableDefaults: true})
+	assert.NoError(t, err)
+	assert.Equal(t, Employee{Name: "Alice", Age: 40, Address: Address{Street: "Main St", City: "Springfield", Height: 1.8}}, person)
+}
+
+func TestFillWithOptions_DisableDefaultsLeavesZeroValues(t *testing.T) {
+	var person Employee
+
+	err := FillWithOptions(&person, map[string]any{}, Options{DisableDefaults: true})
+	assert.NoError(t, err)
+	assert.Equal(t, Employee{}, person)
+}
+
+func TestFillWithOptions_DefaultsOnlyIfZero(t *testing.T) {
+	var person Employee
+	opts := Options{DefaultsOnlyIfZero: true}
+
+	// Base layer
+	err := FillWithOptions(&person, map[string]any{"name": "Alice", "address": map[string]any{"street": "Elm St"}}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, Employee{Name: "Alice", Age: 30, Address: Address{Street: "Elm St", Height: 1.8}}, person)
+
+	// Override layer: only age is given, everything from the first fill survives
+	err = FillWithOptions(&person, map[string]any{"age": 45}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, Employee{Name: "Alice", Age: 45, Address: Address{Street: "Elm St", Height: 1.8}}, person)
+}
+
+func TestFillWithOptions_DisableDefaultsSkipsDefaulter(t *testing.T) {
+	var session Session
+
+	err := Fill(&session, map[string]any{"id": "base", "timeout": 5})
+	assert.NoError(t, err)
+
+	err = FillWithOptions(&session, map[string]any{"user": "bob"}, Options{DisableDefaults: true})
+	assert.NoError(t, err)
+	assert.Equal(t, Session{ID: "base", Timeout: 5, Retries: 1, User: "bob"}, session)
+}
+
+func TestFillWithOptions_DefaultsOnlyIfZeroDefaulter(t *testing.T) {
+	var session Session
+	opts := Options{DefaultsOnlyIfZero: true}
+
+	// Zero fields still get their Defaults
+	err := FillWithOptions(&session, map[string]any{"id": "base"}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, Session{ID: "base", Timeout: 60, Retries: 1}, session)
+
+	err = FillWithOptions(&session, map[string]any{"user": "bob"}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, Session{ID: "base", Timeout: 60, Retries: 1, User: "bob"}, session)
+}
+
+// Generated defaults
+type Ticket struct {
+	ID       string  `defaultfunc:"id"`
+	Sequence uint16  `defaultfunc:"counter"`
+	Ref      *int64  `defaultfunc:"counter"`
+	Title    string  `default:"untitled" defaultfunc:"id"`
+	Owner    string  `defaultfunc:"missing"`
+	Score    float64 `defaultfunc:"id"`
+}
+
+func TestFillWithOptions_DefaultFuncs(t *testing.T) {
+	counter := 0
+	opts := Options{DefaultFuncs: map[string]func() any{
+		"id":      func() any { return "ticket-1" },
+		"counter": func() any { counter++; return counter },
+	}}
+
+	var ticket Ticket
+	err := FillWithOptions(&ticket, map[string]any{"sequence": 7}, opts)
+	assert.NoError(t, err)
+	ref := int64(1)
+	assert.Equal(t, Ticket{ID: "ticket-1", Sequence: 7, Ref: &ref, Title: "untitled"}, ticket)
+
+	// Generators run again for every fill that needs them
+	err = FillWithOptions(&ticket, map[string]any{"id": "ticket-9"}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, "ticket-9", ticket.ID)
+	assert.Equal(t, uint16(2), ticket.Sequence)
+	assert.Equal(t, int64(3), *ticket.Ref)
+}
+
+// Empty containers
+func TestFillWithOptions_EmptyContainers(t *testing.T) {
+	var school School
+
+	err := FillWithOptions(&school, map[string]any{"students": []any{}}, Options{})
+	assert.NoError(t, err)
+	assert.NotNil(t, school.Students)
+	assert.Nil(t, school.Ages)
+	assert.Nil(t, school.Classrooms)
+
+	school = School{}
+	err = FillWithOptions(&school, map[string]any{"students": []any{}, "ages": nil}, Options{EmptyContainers: true})
+	assert.NoError(t, err)
+	data, _ := json.Marshal(school)
+	assert.JSONEq(t, `{"Students": [], "Ages": [], "Classrooms": []}`, string(data))
+}
+
+func TestFillWithOptions_EmptyContainersNested(t *testing.T) {
+	var directory struct {
+		Company Company
+		Prefs   Preferences
+	}
+
+	err := FillWithOptions(&directory, map[string]any{}, Options{EmptyContainers: true})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]Employee{}, directory.Company.Team)
+	assert.Equal(t, []string{}, directory.Prefs.Extra)
+	assert.Equal(t, []string{"a", "b", "c"}, directory.Prefs.Tags, "defaults still win")
+}
+
+// Arrays
+func TestFillWithOptions_TruncateArrays(t *testing.T) {
+	var point Point
+
+	err := FillWithOptions(&point, map[string]any{"coords": []int{1, 2, 3, 4}}, Options{TruncateArrays: true})
+	assert.NoError(t, err)
+	assert.Equal(t, [3]int{1, 2, 3}, point.Coords)
+}
+
+// JSON tags
+type APIUser struct {
+	UserID   int    `json:"user_id"`
+	Nickname string `json:"nick,omitempty"`
+	Email    string `json:",omitempty"`
+	Password string `json:"-"`
+	Role     string `json:"role" fill:"user_role"`
+}
+
+func TestFillWithOptions_UseJSONTags(t *testing.T) {
+	var user APIUser
+	inputMap := map[string]any{
+		"user_id":   7,
+		"nick":      "al",
+		"email":     "al@example.com",
+		"password":  "secret",
+		"user_role": "admin",
+	}
+
+	err := FillWithOptions(&user, inputMap, Options{UseJSONTags: true})
+	assert.NoError(t, err)
+	assert.Equal(t, APIUser{UserID: 7, Nickname: "al", Email: "al@example.com", Role: "admin"}, user)
+}
+
+func TestFillWithOptions_JSONTagsIgnoredByDefault(t *testing.T) {
+	var user APIUser
+
+	err := Fill(&user, map[string]any{"user_id": 7, "userid": 8})
+	assert.NoError(t, err)
+	assert.Equal(t, 8, user.UserID)
+}
+
+func TestFillWithOptions_StrictWithSkippedField(t *testing.T) {
+	var creds Credentials
+	inputMap := map[string]any{
+		"username": "alice",
+		"hash":     "injected",
+	}
+
+	err := FillWithOptions(&creds, inputMap, Options{Strict: true})
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{Username: "alice"}, creds)
+}
+
+// Unknown registry types
+func TestFillWithOptions_DisallowUnknownTypes(t *testing.T) {
+	var house House
+	inputMap := map[string]any{
+		"pets": []map[string]any{
+			{"type": "Dog", "name": "Rex"},
+			{"type": "Parrot", "name": "Polly"},
+		},
+	}
+	opts := Options{
+		DisallowUnknownTypes: true,
+		TypeRegistry: map[string]func() any{
+			"Dog": func() any { return &Dog{} },
+		},
+	}
+
+	err := FillWithOptions(&house, inputMap, opts)
+	assert.Error(t, err)
+	assert.Equal(t, "Pets[1]: type identifier Parrot not found in type registry for field Pets", err.Error())
+}
+
+// Scalar wrapping
+func TestFillWithOptions_WrapScalars(t *testing.T) {
+	var school School
+	inputMap := map[string]any{
+		"students":   "Alice",
+		"ages":       12,
+		"classrooms": map[string]any{"building": "A", "number": 101},
+	}
+
+	err := FillWithOptions(&school, inputMap, Options{WrapScalars: true})
+	assert.NoError(t, err)
+	assert.Equal(t, School{
+		Students:   []string{"Alice"},
+		Ages:       []int{12},
+		Classrooms: []Classroom{{Building: "A", Number: 101}},
+	}, school)
+}
+
+func TestFillWithOptions_ScalarsNotWrappedByDefault(t *testing.T) {
+	var school School
+
+	err := FillWithOptions(&school, map[string]any{"students": "Alice"}, Options{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "expected slice")
+}
+
+// Logging
+func TestFillWithOptions_Logger(t *testing.T) {
+	var house House
+	var warnings []string
+	inputMap := map[string]any{
+		"pets": []map[string]any{
+			{"type": "Parrot", "name": "Polly"},
+		},
+	}
+	opts := Options{
+		TypeRegistry: map[string]func() any{
+			"Dog": func() any { return &Dog{} },
+		},
+		Logger: func(format string, args ...any) {
+			warnings = append(warnings, fmt.Sprintf(format, args...))
+		},
+	}
+
+	err := FillWithOptions(&house, inputMap, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"warning: type identifier Parrot not found in type registry, skipping"}, warnings)
+}
+
+func TestFillWithOptions_StrictEmbeddedInterface(t *testing.T) {
+	var enclosure Enclosure
+	opts := Options{
+		Strict: true,
+		TypeRegistry: map[string]func() any{
+			"Dog": func() any { return &Dog{} },
+		},
+	}
+
+	err := FillWithOptions(&enclosure, map[string]any{"type": "Dog", "name": "Rex", "keeper": "Bob"}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, Enclosure{Animal: &Dog{Pet{Name: "Rex"}}, Keeper: "Bob"}, enclosure)
+
+	err = FillWithOptions(&enclosure, map[string]any{"type": "Dog", "wild": true}, opts)
+	assert.Error(t, err)
+	assert.Equal(t, "unknown keys in input map: wild", err.Error())
+}
+
+// Appending slices
+func TestFillWithOptions_AppendSlices(t *testing.T) {
+	school := School{Students: []string{"Alice"}}
+	inputMap := map[string]any{
+		"students":   []string{"Bob", "Carol"},
+		"classrooms": []map[string]any{{"building": "A", "number": 101}},
+	}
+
+	err := FillWithOptions(&school, inputMap, Options{AppendSlices: true})
+	assert.NoError(t, err)
+	assert.Equal(t, School{
+		Students:   []string{"Alice", "Bob", "Carol"},
+		Classrooms: []Classroom{{Building: "A", Number: 101}},
+	}, school)
+}
+
+func TestFillWithOptions_ReplaceSlicesByDefault(t *testing.T) {
+	school := School{Students: []string{"Alice"}}
+
+	err := FillWithOptions(&school, map[string]any{"students": []string{"Bob"}}, Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Bob"}, school.Students)
+}
+
+// Bool coercion
+type Toggles struct {
+	Enabled int
+	Count   uint8
+	Active  bool
+}
+
+func TestFillWithOptions_CoerceBools(t *testing.T) {
+	var toggles Toggles
+	inputMap := map[string]any{
+		"enabled": true,
+		"count":   false,
+		"active":  1,
+	}
+
+	err := FillWithOptions(&toggles, inputMap, Options{CoerceBools: true})
+	assert.NoError(t, err)
+	assert.Equal(t, Toggles{Enabled: 1, Count: 0, Active: true}, toggles)
+
+	err = FillWithOptions(&toggles, map[string]any{"active": 0.0}, Options{CoerceBools: true})
+	assert.NoError(t, err)
+	assert.False(t, toggles.Active)
+}
+
+func TestFillWithOptions_CoerceBoolsRejectsAmbiguousNumbers(t *testing.T) {
+	var toggles Toggles
+
+	err := FillWithOptions(&toggles, map[string]any{"active": 2}, Options{CoerceBools: true})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid boolean "2"`)
+}
+
+func TestFillWithOptions_BoolsNotCoercedToIntsByDefault(t *testing.T) {
+	var toggles Toggles
+
+	err := FillWithOptions(&toggles, map[string]any{"enabled": true}, Options{})
+	assert.Error(t, err)
+}
+
+// Nesting depth
+type TreeNode struct {
+	Value    int
+	Children []TreeNode
+	Parent   *TreeNode
+}
+
+func nestedTreeInput(depth int) map[string]any {
+	node := map[string]any{"value": depth}
+	for i := depth - 1; i > 0; i-- {
+		node = map[string]any{"value": i, "children": []any{node}}
+	}
+	return node
+}
+
+func TestFillWithOptions_MaxDepth(t *testing.T) {
+	var tree TreeNode
+
+	err := FillWithOptions(&tree, nestedTreeInput(3), Options{MaxDepth: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, tree.Children[0].Children[0].Value)
+
+	err = FillWithOptions(&tree, nestedTreeInput(4), Options{MaxDepth: 3})
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrMaxDepth))
+	assert.Equal(t, "Children[0].Children[0].Children[0]: maximum nesting depth exceeded: more than 3 levels", err.Error())
+}
+
+func TestFillWithOptions_DefaultMaxDepth(t *testing.T) {
+	var tree TreeNode
+
+	err := FillWithOptions(&tree, nestedTreeInput(64), Options{})
+	assert.NoError(t, err)
+
+	err = FillWithOptions(&tree, map[string]any{"parent": nestedTreeInput(64)}, Options{})
+	assert.True(t, errors.Is(err, ErrMaxDepth))
+}
+
+type LinkedNode struct {
+	Value int         `default:"1"`
+	Next  *LinkedNode `default:"{}"`
+}
+
+func TestFillWithOptions_MaxDepthSelfReferencingDefault(t *testing.T) {
+	var node LinkedNode
+
+	err := FillWithOptions(&node, map[string]any{}, Options{MaxDepth: 3})
+	assert.True(t, errors.Is(err, ErrMaxDepth))
+	assert.Equal(t, "Next.Next.Next: maximum nesting depth exceeded: more than 3 levels", err.Error())
+
+	node = LinkedNode{}
+	err = ApplyDefaults(&node)
+	assert.True(t, errors.Is(err, ErrMaxDepth))
+}
+
+type Innermost struct{ Value int }
+type Middle struct{ Innermost }
+type Outermost struct{ *Middle }
+
+func TestFillWithOptions_MaxDepthCountsEmbedding(t *testing.T) {
+	var level Outermost
+
+	err := FillWithOptions(&level, map[string]any{"value": 1}, Options{MaxDepth: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, level.Value)
+
+	err = FillWithOptions(&level, map[string]any{"value": 1}, Options{MaxDepth: 2})
+	assert.True(t, errors.Is(err, ErrMaxDepth))
+}
+
+// Time layouts
+func TestFillWithOptions_TimeLayouts(t *testing.T) {
+	var event Event
+	opts := Options{TimeLayouts: []string{time.RFC3339, "02 Jan 2006"}}
+
+	err := FillWithOptions(&event, map[string]any{"start": "01 Mar 2024", "day": "2024-03-02"}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), event.Start)
+	// A format tag still takes precedence
+	assert.Equal(t, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), event.Day)
+}
+
+// Dotted keys
+func TestFillWithOptions_ExpandDottedKeys(t *testing.T) {
+	var department Department
+	inputMap := map[string]any{
+		"lead.name":           "Alice",
+		"lead.address.city":   "Springfield",
+		"lead.address.street": "Elm St",
+		"lead":                map[string]any{"age": 40, "name": "ignored"},
+	}
+
+	err := FillWithOptions(&department, inputMap, Options{ExpandDottedKeys: true, Strict: true})
+	assert.NoError(t, err)
+	assert.Equal(t, Employee{Name: "Alice", Age: 40, Address: Address{Street: "Elm St", City: "Springfield", Height: 1.8}}, department.Lead)
+	// The caller's nested map is not modified
+	assert.Equal(t, map[string]any{"age": 40, "name": "ignored"}, inputMap["lead"])
+}
+
+func TestFillWithOptions_ExpandDottedKeysConflict(t *testing.T) {
+	var department Department
+
+	err := FillWithOptions(&department, map[string]any{"lead": "Alice", "lead.name": "Bob"}, Options{ExpandDottedKeys: true})
+	assert.Error(t, err)
+	assert.Equal(t, "dotted key lead.name conflicts with non-map value for lead", err.Error())
+}
+
+func TestFillWithOptions_DottedKeysLiteralByDefault(t *testing.T) {
+	var department Department
+
+	err := FillWithOptions(&department, map[string]any{"lead.name": "Alice"}, Options{Strict: true})
+	assert.Error(t, err)
+	assert.Equal(t, "unknown keys in input map: lead.name", err.Error())
+}
+
+// Unexported fields
+type Wallet struct {
+	Owner   string
+	balance float64
+	pin     string `fill:"-"`
+}
+
+func TestFillWithOptions_ReportUnexported(t *testing.T) {
+	var wallet Wallet
+	var warnings []string
+	opts := Options{
+		ReportUnexported: true,
+		Logger: func(format string, args ...any) {
+			warnings = append(warnings, fmt.Sprintf(format, args...))
+		},
+	}
+
+	err := FillWithOptions(&wallet, map[string]any{"owner": "Ann", "balance": 12.5, "pin": "1234"}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, Wallet{Owner: "Ann"}, wallet)
+	assert.Equal(t, []string{"warning: input key balance matches unexported field balance, skipping"}, warnings)
+
+	warnings = nil
+	err = FillWithOptions(&wallet, map[string]any{"owner": "Ann"}, opts)
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+}
+
+type Vault struct {
+	secret string
+	code   string
+}
+
+type Safe struct {
+	Vault
+	Secret string
+}
+
+func TestFillWithOptions_ReportUnexportedEmbedded(t *testing.T) {
+	var safe Safe
+	var warnings []string
+	opts := Options{
+		ReportUnexported: true,
+		Logger: func(format string, args ...any) {
+			warnings = append(warnings, fmt.Sprintf(format, args...))
+		},
+	}
+
+	err := FillWithOptions(&safe, map[string]any{"secret": "s3cret", "code": "42"}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cret", safe.Secret)
+	// The outer Secret consumes its key even though it comes after the embedded struct
+	assert.Equal(t, []string{"warning: input key code matches unexported field code, skipping"}, warnings)
+}
+
+// Strict types
+func TestFillWithOptions_StrictTypes(t *testing.T) {
+	var person Employee
+	opts := Options{StrictTypes: true}
+
+	err := FillWithOptions(&person, map[string]any{"name": "Alice", "age": 29.0}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, "Alice", person.Name)
+	assert.Equal(t, 29, person.Age)
+
+	err = FillWithOptions(&person, map[string]any{"age": json.Number("31")}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 31, person.Age)
+
+	err = FillWithOptions(&person, map[string]any{"age": "29"}, opts)
+	assert.Error(t, err)
+	assert.Equal(t, "Age: invalid type for field Age, expected int but got string", err.Error())
+
+	err = FillWithOptions(&person, map[string]any{"name": true}, opts)
+	assert.Error(t, err)
+	assert.Equal(t, "Name: invalid type for field Name, expected string but got bool", err.Error())
+}
+
+func TestFillWithOptions_StrictTypesRejectsFractions(t *testing.T) {
+	var person Employee
+	opts := Options{StrictTypes: true}
+
+	err := FillWithOptions(&person, map[string]any{"age": 29.7}, opts)
+	assert.Error(t, err)
+	assert.Equal(t, "Age: value 29.7 has a fractional part", err.Error())
+
+	err = FillWithOptions(&person, map[string]any{"age": json.Number("29.5")}, opts)
+	assert.Error(t, err)
+	assert.Equal(t, "Age: value 29.5 has a fractional part", err.Error())
+}
+
+func TestFillWithOptions_StrictTypesOverridesCoercion(t *testing.T) {
+	var toggles Toggles
+	opts := Options{StrictTypes: true, CoerceBools: true, LenientBools: true}
+
+	err := FillWithOptions(&toggles, map[string]any{"active": true, "count": 3}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, Toggles{Count: 3, Active: true}, toggles)
+
+	err = FillWithOptions(&toggles, map[string]any{"enabled": true}, opts)
+	assert.Error(t, err)
+	assert.Equal(t, "Enabled: invalid type for field Enabled, expected int but got bool", err.Error())
+
+	err = FillWithOptions(&toggles, map[string]any{"active": "yes"}, opts)
+	assert.Error(t, err)
+	assert.Equal(t, "Active: invalid type for field Active, expected bool but got string", err.Error())
+}
+
+// Unsupported types
+type Worker struct {
+	Name    string
+	Jobs    chan int
+	OnDone  func()
+	Retries int
+}
+
+func TestFillWithOptions_SkipUnsupported(t *testing.T) {
+	var worker Worker
+	var warnings []string
+	opts := Options{
+		SkipUnsupported: true,
+		Logger: func(format string, args ...any) {
+			warnings = append(warnings, fmt.Sprintf(format, args...))
+		},
+	}
+
+	err := FillWithOptions(&worker, map[string]any{"name": "w1", "jobs": make(chan int), "ondone": "noop", "retries": 3}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, "w1", worker.Name)
+	assert.Nil(t, worker.Jobs)
+	assert.Nil(t, worker.OnDone)
+	assert.Equal(t, 3, worker.Retries)
+	assert.Equal(t, []string{
+		"warning: field Jobs has unsupported type chan int, skipping",
+		"warning: field OnDone has unsupported type func(), skipping",
+	}, warnings)
+
+	err = FillWithOptions(&worker, map[string]any{"jobs": make(chan int)}, Options{})
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestFillWithOptions_SkipUnsupportedNotTracked(t *testing.T) {
+	var worker Worker
+	f := &filler{opts: Options{SkipUnsupported: true, Logger: func(string, ...any) {}}}
+
+	err := f.fill(&worker, map[string]any{"name": "w1", "jobs": make(chan int), "retries": 3}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Name", "Retries"}, f.filled)
+}
+
+// Type resolvers
+func resolveAnimal(elem map[string]any) (any, error) {
+	switch {
+	case elem["wild"] != nil:
+		return &Cat{}, nil
+	case elem["name"] != nil:
+		return &Dog{}, nil
+	}
+	return nil, fmt.Errorf("can't tell animal type from keys")
+}
+
+func TestFillWithOptions_TypeResolvers(t *testing.T) {
+	var house House
+	inputMap := map[string]any{
+		"pets": []any{
+			map[string]any{"name": "Rex"},
+			map[string]any{"name": "Tom", "wild": false},
+		},
+	}
+	opts := Options{
+		TypeResolvers: map[reflect.Type]TypeResolver{
+			reflect.TypeOf((*Animal)(nil)).Elem(): resolveAnimal,
+		},
+	}
+
+	err := FillWithOptions(&house, inputMap, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, House{Pets: []Animal{&Dog{Pet{Name: "Rex"}}, &Cat{Pet: Pet{Name: "Tom"}}}}, house)
+	assert.Equal(t, "Meow!", house.Pets[1].Speak())
+
+	var owner Owner
+	err = FillWithOptions(&owner, map[string]any{"favorite": map[string]any{"name": "Rex"}}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, &Dog{Pet{Name: "Rex"}}, owner.Favorite)
+}
+
+func TestFillWithOptions_TypeResolversError(t *testing.T) {
+	var house House
+	opts := Options{
+		TypeResolvers: map[reflect.Type]TypeResolver{
+			reflect.TypeOf((*Animal)(nil)).Elem(): resolveAnimal,
+		},
+	}
+
+	err := FillWithOptions(&house, map[string]any{"pets": []any{map[string]any{"name": "Rex"}, map[string]any{}}}, opts)
+	assert.Error(t, err)
+	assert.Equal(t, "Pets[1]: can't tell animal type from keys", err.Error())
+}
@@ -0,0 +1,115 @@
+package structfill
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+)
+
+// FillFromStruct fills dst from the exported fields of src, a struct or a
+// pointer to one, as if src had been decoded into an input map first. Each
+// src field is stored under the key Fill would read it from (its fill tag
+// name or lowercased field name), so fields with the same name are copied
+// and converted, and dst's defaults and validation rules apply as usual.
+// Nested structs become nested maps, nil pointers count as missing keys and
+// values implementing encoding.TextMarshaler (other than time.Time) are
+// passed as their text.
+func FillFromStruct(dst any, src any, _typeRegistry ...map[string]func() any) error {
+	srcVal := reflect.ValueOf(src)
+	if srcVal.Kind() == reflect.Ptr {
+		srcVal = srcVal.Elem()
+	}
+	if srcVal.Kind() != reflect.Struct {
+		return fmt.Errorf("source must be a struct or a pointer to a struct, got %T", src)
+	}
+	return Fill(dst, structToMap(srcVal), _typeRegistry...)
+}
+
+// structToMap converts a struct value into the input map Fill would need to
+// fill an identical struct.
+func structToMap(structVal reflect.Value) map[string]any {
+	f := &filler{}
+	fields := cachedFields(structVal.Type())
+	inputMap := make(map[string]any, len(fields))
+	var embedded []reflect.Value
+	for i := range fields {
+		info := &fields[i]
+		field := structVal.Field(info.index)
+		if info.embedded {
+			embedded = append(embedded, field)
+			continue
+		}
+		if info.skip || info.remaining || info.embeddedInterface {
+			continue
+		}
+		if key, ok := f.fieldKey(info); ok {
+			inputMap[key] = toInput(field)
+		}
+	}
+	// Promoted fields are added last so the embedding struct's fields win
+	for _, field := range embedded {
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				continue
+			}
+			field = field.Elem()
+		}
+		for key, value := range structToMap(field) {
+			if _, ok := inputMap[key]; !ok {
+				inputMap[key] = value
+			}
+		}
+	}
+	return inputMap
+}
+
+// toInput converts a field value of a source struct into an input map value.
+func toInput(value reflect.Value) any {
+	if !value.IsValid() {
+		return nil
+	}
+	if value.Type() == timeType {
+		return value.Interface()
+	}
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return toInput(value.Elem())
+	}
+	if marshaler, ok := value.Interface().(encoding.TextMarshaler); ok {
+		if text, err := marshaler.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		return structToMap(value)
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return nil
+		}
+		elems := make([]any, value.Len())
+		for i := range elems {
+			elems[i] = toInput(value.Index(i))
+		}
+		return elems
+	case reflect.Map:
+		if value.IsNil() {
+			return nil
+		}
+		converted := reflect.MakeMapWithSize(reflect.MapOf(value.Type().Key(), anyType), value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			elem := reflect.ValueOf(toInput(iter.Value()))
+			if !elem.IsValid() {
+				elem = reflect.Zero(anyType)
+			}
+			converted.SetMapIndex(iter.Key(), elem)
+		}
+		return converted.Interface()
+	}
+	return value.Interface()
+}
@@ -0,0 +1,94 @@
+package structfill
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type UserDTO struct {
+	Name     string
+	Age      float64
+	Email    *string
+	Address  map[string]string
+	Tags     [2]string
+	Joined   time.Time
+	Server   netip.Addr
+	Teams    []TeamDTO
+	Internal string `fill:"-"`
+}
+
+type TeamDTO struct {
+	Title string
+	Size  int64
+}
+
+type Member struct {
+	Name    string
+	Age     int    `validate:"min=18"`
+	Email   string `default:"unknown@example.com"`
+	Address Address
+	Tags    []string
+	Joined  time.Time `format:"2006-01-02"`
+	Server  netip.Addr
+	Teams   []Team
+}
+
+type Team struct {
+	Title string
+	Size  uint8
+}
+
+func TestFillFromStruct(t *testing.T) {
+	joined := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+	dto := UserDTO{
+		Name:     "Alice",
+		Age:      29,
+		Address:  map[string]string{"city": "Springfield"},
+		Tags:     [2]string{"admin", "ops"},
+		Joined:   joined,
+		Server:   netip.MustParseAddr("10.0.0.1"),
+		Teams:    []TeamDTO{{Title: "core", Size: 4}},
+		Internal: "secret",
+	}
+
+	var member Member
+	err := FillFromStruct(&member, &dto)
+	assert.NoError(t, err)
+	assert.Equal(t, Member{
+		Name:    "Alice",
+		Age:     29,
+		Email:   "unknown@example.com",
+		Address: Address{Street: "Main St", City: "Springfield", Height: 1.8},
+		Tags:    []string{"admin", "ops"},
+		Joined:  joined,
+		Server:  netip.MustParseAddr("10.0.0.1"),
+		Teams:   []Team{{Title: "core", Size: 4}},
+	}, member)
+}
+
+func TestFillFromStruct_Validation(t *testing.T) {
+	var member Member
+
+	err := FillFromStruct(&member, UserDTO{Age: 12})
+	assert.Error(t, err)
+	assert.Equal(t, "Age: value 12 is less than min 18", err.Error())
+}
+
+func TestFillFromStruct_EmbeddedFields(t *testing.T) {
+	var dog Dog
+
+	err := FillFromStruct(&dog, struct{ Pet }{Pet{Name: "Rex"}})
+	assert.NoError(t, err)
+	assert.Equal(t, Dog{Pet{Name: "Rex"}}, dog)
+}
+
+func TestFillFromStruct_NotStruct(t *testing.T) {
+	var member Member
+
+	err := FillFromStruct(&member, map[string]any{"name": "Alice"})
+	assert.Error(t, err)
+	assert.Equal(t, "source must be a struct or a pointer to a struct, got map[string]interface {}", err.Error())
+}
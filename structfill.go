@@ -1,218 +1,1660 @@
 package structfill
 
 import (
+	"database/sql"
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"math"
+	"os"
 	"reflect"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	durationType   = reflect.TypeOf(time.Duration(0))
+	jsonNumberType = reflect.TypeOf(json.Number(""))
+	anyType        = reflect.TypeOf((*any)(nil)).Elem()
+
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Fillable is implemented by types that fill themselves from a nested input
+// map instead of having their fields filled by reflection. It is consulted for
+// map input only, after encoding.TextUnmarshaler (which handles string input)
+// and before the built-in handling of the field's kind.
+type Fillable interface {
+	Fill(inputMap map[string]any) error
+}
+
+// Defaulter is implemented by structs that compute their own defaults. When
+// such a struct is filled from a map, the keys returned by Defaults that are
+// missing from that map are filled afterwards, so input values take
+// precedence over Defaults, which in turn take precedence over default tags.
+// Like default tags, Defaults are skipped under Options.DisableDefaults and
+// only fill zero fields under Options.DefaultsOnlyIfZero.
+// A nested struct without a key in the input, and a struct passed to
+// ApplyDefaults, get their Defaults as if filled from an empty map.
+// Defaulted fields are not reported by FillTracked.
+type Defaulter interface {
+	Defaults() map[string]any
+}
+
+// Fill populates the struct pointed to by structType from inputMap, matching
+// keys against lowercased field names (see MatchLowercase). An optional type
+// registry resolves the concrete types of interface slice elements. A nil
+// inputMap is treated as empty, as is a nil value or nil map for a nested
+// struct, so defaults still apply at every level.
+//
+// Fields are filled in declaration order, with the fields of an embedded
+// struct filled at the position of the embedded field, and nested structs
+// filled when their field is reached. The error returned for invalid input
+// is always the one for the first failing field in that order, never
+// depending on map iteration, so errors are reproducible across runs.
 func Fill(structType any, inputMap map[string]any, _typeRegistry ...map[string]func() any) error {
 	typeRegistry := make(map[string]func() any)
 	if len(_typeRegistry) > 0 {
 		typeRegistry = _typeRegistry[0]
 	}
+	return FillWithOptions(structType, inputMap, Options{TypeRegistry: typeRegistry})
+}
 
+// FillChecked allocates a T, fills it like Fill and returns it by value. T
+// must be a struct type.
+func FillChecked[T any](inputMap map[string]any, _typeRegistry ...map[string]func() any) (T, error) {
+	var value T
+	err := Fill(&value, inputMap, _typeRegistry...)
+	return value, err
+}
+
+// FillWithOptions is like Fill but lets the caller control how the struct is
+// filled through opts.
+func FillWithOptions(structType any, inputMap map[string]any, opts Options) error {
+	f := &filler{opts: opts}
+	return f.fill(structType, inputMap, "")
+}
+
+// FillTracked is like Fill but also returns the dotted paths of the fields
+// whose values came from inputMap, as opposed to defaults or zero values.
+// Paths are listed in struct field order, with a nested struct's own path
+// preceding the paths of its fields (e.g. "Address", "Address.City").
+func FillTracked(structType any, inputMap map[string]any, _typeRegistry ...map[string]func() any) ([]string, error) {
+	typeRegistry := make(map[string]func() any)
+	if len(_typeRegistry) > 0 {
+		typeRegistry = _typeRegistry[0]
+	}
+	f := &filler{opts: Options{TypeRegistry: typeRegistry}}
+	err := f.fill(structType, inputMap, "")
+	return f.filled, err
+}
+
+// FillMasked is like Fill but only writes the fields named in mask that have
+// a key in inputMap, for PATCH-style partial updates. Every other field is
+// left untouched.
+//
+// Mask entries are dotted field paths as reported by FillTracked. "Address"
+// allows the whole nested struct, while "Address.City" allows just that
+// field of it. Embedded fields count as fields of the embedding struct.
+//
+// A nested struct pointer that is already set is updated in place. Slices
+// and maps are replaced whole, so a mask such as "Items.Name" fills every
+// field of the new elements.
+//
+// No defaults are applied, including those of Defaulter. Masked fields
+// without a key are left as they are, even if they are required.
+//
+// An embedded interface is only filled when its type name, e.g. "Animal", is
+// in mask.
+func FillMasked(structPtr any, inputMap map[string]any, mask []string, _typeRegistry ...map[string]func() any) error {
+	typeRegistry := make(map[string]func() any)
+	if len(_typeRegistry) > 0 {
+		typeRegistry = _typeRegistry[0]
+	}
+	f := &filler{
+		opts: Options{TypeRegistry: typeRegistry, DisableDefaults: true},
+		mask: make(map[string]bool, len(mask)),
+	}
+	for _, path := range mask {
+		f.mask[path] = true
+	}
+	return f.fill(structPtr, inputMap, "")
+}
+
+// FillSlice populates the slice pointed to by slicePtr from input, a slice
+// such as []any or []map[string]any, filling each element the way the
+// elements of a slice field are filled. Struct elements are filled from maps.
+func FillSlice(slicePtr any, input any, _typeRegistry ...map[string]func() any) error {
+	typeRegistry := make(map[string]func() any)
+	if len(_typeRegistry) > 0 {
+		typeRegistry = _typeRegistry[0]
+	}
+	return fillContainer(slicePtr, reflect.Slice, input, Options{TypeRegistry: typeRegistry})
+}
+
+// FillMap populates the map pointed to by mapPtr from inputMap, filling each
+// value the way the values of a map field are filled. Struct values are filled
+// from nested maps.
+func FillMap(mapPtr any, inputMap map[string]any, _typeRegistry ...map[string]func() any) error {
+	typeRegistry := make(map[string]func() any)
+	if len(_typeRegistry) > 0 {
+		typeRegistry = _typeRegistry[0]
+	}
+	return fillContainer(mapPtr, reflect.Map, inputMap, Options{TypeRegistry: typeRegistry})
+}
+
+// ConvertValue converts value to the target type with the rules Fill uses for
+// slice elements and map values: nil becomes the zero value, numbers and
+// json.Numbers are parsed for numeric types like numeric fields, so 300 is an
+// overflow error for int8 and 2.9 is truncated to 2 for int, and anything else
+// must be convertible by Go's conversion rules. Numbers are never converted to
+// strings, which Go would treat as runes.
+func ConvertValue(value any, target reflect.Type) (any, error) {
+	f := &filler{}
+	converted, err := f.convertValue(reflect.ValueOf(value), target)
+	if err != nil {
+		return nil, err
+	}
+	return converted.Interface(), nil
+}
+
+// fillContainer fills the slice or map pointed to by ptr as if it were a field
+// named after its type.
+func fillContainer(ptr any, kind reflect.Kind, input any, opts Options) error {
+	val := reflect.ValueOf(ptr)
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != kind {
+		return fmt.Errorf("provided type must be a pointer to a %v", kind)
+	}
+	target := val.Elem()
+	info := &fieldInfo{field: reflect.StructField{Name: target.Type().String(), Type: target.Type()}}
+	f := &filler{opts: opts}
+	return f.setFieldValue(target, info, input, "")
+}
+
+// ApplyDefaults sets every field of the struct pointed to by structPtr that
+// has a default (or env) tag, including fields of nested and embedded structs,
+// as if it were filled from an empty input map. Like Fill, it leaves nil
+// embedded struct pointers nil.
+func ApplyDefaults(structPtr any) error {
+	structVal := reflect.ValueOf(structPtr)
+	if structVal.Kind() != reflect.Ptr || structVal.Elem().Kind() != reflect.Struct {
+		return ErrNotStructPointer
+	}
+	f := &filler{}
+	return f.setDefaultValues(structVal.Elem(), "", "")
+}
+
+// filler carries the options for a single fill through the recursion.
+type filler struct {
+	opts Options
+
+	// filled collects the paths of fields set from the input map.
+	filled []string
+	// depth is the number of structs currently being filled.
+	depth int
+	// mask holds the field paths FillMasked may write, or nil for every field.
+	mask map[string]bool
+	// zeroOnly restricts the fill to fields holding their zero value, for
+	// Defaults under Options.DefaultsOnlyIfZero.
+	zeroOnly bool
+}
+
+// defaultMaxDepth is the nesting limit used when Options.MaxDepth is zero.
+const defaultMaxDepth = 64
+
+// fill does the work of Fill, prefixing every field path with path so errors
+// from nested structs report where they happened. Any knownKeys are treated
+// as consumed in strict mode even though no field matches them.
+func (f *filler) fill(structType any, inputMap map[string]any, path string, knownKeys ...string) error {
 	structVal := reflect.ValueOf(structType)
 	if structVal.Kind() != reflect.Ptr || structVal.Elem().Kind() != reflect.Struct {
-		return errors.New("provided type must be a pointer to a struct")
+		return ErrNotStructPointer
+	}
+
+	if err := f.enter(path); err != nil {
+		return err
+	}
+	defer func() { f.depth-- }()
+
+	if f.opts.ExpandDottedKeys {
+		expanded, err := expandDottedKeys(inputMap)
+		if err != nil {
+			return wrapFieldError(path, err)
+		}
+		inputMap = expanded
+	}
+
+	state := &structState{consumed: make(map[string]bool)}
+	if f.opts.KeyMatching == MatchCaseInsensitive {
+		keyIndex, err := buildKeyIndex(inputMap)
+		if err != nil {
+			return wrapFieldError(path, err)
+		}
+		state.keyIndex = keyIndex
+	}
+	for _, key := range knownKeys {
+		state.consumed[key] = true
+	}
+	defaulter, hasDefaults := structType.(Defaulter)
+	hasDefaults = hasDefaults && !f.opts.DisableDefaults
+	if hasDefaults && f.opts.DefaultsOnlyIfZero {
+		// Apply Defaults first so default tags don't make their fields non-zero
+		if err := f.applyDefaulter(structType, defaulter, inputMap, path, state.keyIndex); err != nil {
+			return err
+		}
+	}
+	if err := f.fillFields(structVal.Elem(), inputMap, path, state); err != nil {
+		return err
+	}
+	for _, filledType := range state.filledTypes {
+		// Report once every field, including later ones, has consumed its key
+		f.reportUnexported(filledType, inputMap, path, state)
+	}
+	if state.remaining.IsValid() {
+		if err := collectRemaining(state, inputMap, path); err != nil {
+			return err
+		}
+	}
+	if f.opts.Strict {
+		if err := checkUnknownKeys(inputMap, state.consumed, path); err != nil {
+			return err
+		}
+	}
+	if hasDefaults && !f.opts.DefaultsOnlyIfZero {
+		return f.applyDefaulter(structType, defaulter, inputMap, path, state.keyIndex)
+	}
+	return nil
+}
+
+// enter counts one more level of nesting for the struct at path, failing once
+// Options.MaxDepth is exceeded. Callers decrement f.depth when they leave it.
+func (f *filler) enter(path string) error {
+	maxDepth := f.opts.MaxDepth
+	if maxDepth == 0 {
+		maxDepth = defaultMaxDepth
+	}
+	if f.depth >= maxDepth {
+		return wrapFieldError(path, fmt.Errorf("%w: more than %d levels", ErrMaxDepth, maxDepth))
+	}
+	f.depth++
+	return nil
+}
+
+// buildKeyIndex maps the lowercased keys of inputMap to the keys themselves
+// for MatchCaseInsensitive. Keys that only differ by case are ambiguous and
+// reported together in the error.
+func buildKeyIndex(inputMap map[string]any) (map[string]string, error) {
+	keyIndex := make(map[string]string, len(inputMap))
+	var ambiguous []string
+	for key := range inputMap {
+		lower := strings.ToLower(key)
+		if other, ok := keyIndex[lower]; ok {
+			if other != "" {
+				ambiguous = append(ambiguous, other)
+				// Blank the entry so a third spelling isn't listed twice
+				keyIndex[lower] = ""
+			}
+			ambiguous = append(ambiguous, key)
+			continue
+		}
+		keyIndex[lower] = key
+	}
+	if len(ambiguous) > 0 {
+		sort.Strings(ambiguous)
+		return nil, fmt.Errorf("ambiguous keys in input map: %s", strings.Join(ambiguous, ", "))
+	}
+	return keyIndex, nil
+}
+
+// expandDottedKeys turns keys such as "address.city" into nested maps, merging
+// them with any nested map already under the same key. A dotted key takes
+// precedence over the same key in that map. inputMap itself is not modified.
+func expandDottedKeys(inputMap map[string]any) (map[string]any, error) {
+	hasDots := false
+	for key := range inputMap {
+		if strings.Contains(key, ".") {
+			hasDots = true
+			break
+		}
+	}
+	if !hasDots {
+		return inputMap, nil
+	}
+
+	// Copy plain keys first so dotted keys are merged on top of them
+	expanded := make(map[string]any, len(inputMap))
+	for key, value := range inputMap {
+		if !strings.Contains(key, ".") {
+			expanded[key] = value
+		}
+	}
+	dotted := make([]string, 0, len(inputMap))
+	for key := range inputMap {
+		if strings.Contains(key, ".") {
+			dotted = append(dotted, key)
+		}
+	}
+	sort.Strings(dotted)
+	for _, key := range dotted {
+		parts := strings.Split(key, ".")
+		current := expanded
+		for i, part := range parts[:len(parts)-1] {
+			switch next := current[part].(type) {
+			case nil:
+				nested := make(map[string]any)
+				current[part] = nested
+				current = nested
+			case map[string]any:
+				// Copy so the caller's nested map is left alone
+				nested := make(map[string]any, len(next))
+				for k, v := range next {
+					nested[k] = v
+				}
+				current[part] = nested
+				current = nested
+			default:
+				return nil, fmt.Errorf("dotted key %s conflicts with non-map value for %s", key, strings.Join(parts[:i+1], "."))
+			}
+		}
+		current[parts[len(parts)-1]] = inputMap[key]
+	}
+	return expanded, nil
+}
+
+// applyDefaulter fills the keys from a Defaulter's Defaults that are missing
+// from inputMap, leaving every other field as it is. keyIndex is the fill's
+// index of inputMap for MatchCaseInsensitive, so a key counts as present in
+// any spelling.
+func (f *filler) applyDefaulter(structType any, defaulter Defaulter, inputMap map[string]any, path string, keyIndex map[string]string) error {
+	missing := make(map[string]any)
+	for key, value := range defaulter.Defaults() {
+		present := false
+		if keyIndex != nil {
+			_, present = keyIndex[strings.ToLower(key)]
+		} else {
+			_, present = inputMap[key]
+		}
+		if !present {
+			missing[key] = value
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	// Use a separate filler so defaulted fields are not tracked as filled
+	opts := f.opts
+	opts.DisableDefaults = true
+	defaults := &filler{opts: opts, depth: f.depth, zeroOnly: f.opts.DefaultsOnlyIfZero}
+	state := &structState{consumed: make(map[string]bool)}
+	if opts.KeyMatching == MatchCaseInsensitive {
+		missingIndex, err := buildKeyIndex(missing)
+		if err != nil {
+			return wrapFieldError(path, err)
+		}
+		state.keyIndex = missingIndex
+	}
+	return defaults.fillFields(reflect.ValueOf(structType).Elem(), missing, path, state)
+}
+
+// structState tracks a single struct being filled, including the structs it
+// embeds, which share its input map.
+type structState struct {
+	// consumed holds the input keys matched by a field.
+	consumed map[string]bool
+	// keyIndex maps lowercased input keys to the keys themselves when
+	// matching with MatchCaseInsensitive.
+	keyIndex map[string]string
+	// shadowed holds the keys of fields declared in the structs that embed
+	// the one being filled, which take precedence over its own fields.
+	shadowed map[string]bool
+	// filledTypes holds the struct and the embedded structs whose fields
+	// were filled, for Options.ReportUnexported.
+	filledTypes []reflect.Type
+	// remaining is the field tagged `fill:",remaining"`, if any.
+	remaining     reflect.Value
+	remainingInfo *fieldInfo
+}
+
+// fillFields fills each settable field of structVal, recording the input keys
+// it matched in state. Embedded structs share the input map and state of the
+// struct that embeds them. Fields are visited in declaration order, with an
+// embedded struct's fields expanded at its position, and the first error
+// stops the fill; Fill documents this ordering.
+func (f *filler) fillFields(structVal reflect.Value, inputMap map[string]any, path string, state *structState) error {
+	fields := cachedFields(structVal.Type())
+	var shadowed map[string]bool
+	shadowedKnown := false
+	if f.opts.ReportUnexported {
+		state.filledTypes = append(state.filledTypes, structVal.Type())
+	}
+
+	for i := range fields {
+		info := &fields[i]
+		field := structVal.Field(info.index)
+
+		if info.embedded {
+			if !shadowedKnown {
+				shadowed, shadowedKnown = f.shadowedKeys(fields, state), true
+			}
+			// Promoted fields keep the path of the embedding struct
+			outer := state.shadowed
+			state.shadowed = shadowed
+			err := f.fillEmbedded(field, inputMap, path, state)
+			state.shadowed = outer
+			if err != nil {
+				return err
+			}
+		} else if info.embeddedInterface {
+			if !f.allowed(joinPath(path, info.field.Name)) {
+				continue
+			}
+			if err := f.fillEmbeddedInterface(field, info, inputMap, path, state); err != nil {
+				return err
+			}
+		} else if info.skip {
+			// Never filled, but its key is not reported as unknown in strict mode
+			if key, ok := f.inputKey(info, state); ok {
+				state.consumed[key] = true
+			}
+		} else if info.remaining {
+			if !f.allowed(joinPath(path, info.field.Name)) {
+				continue
+			}
+			// Filled with the unmatched keys once every other field is done
+			state.remaining = field
+			state.remainingInfo = info
+		} else {
+			key, ok := f.inputKey(info, state)
+			if ok && state.shadowed[key] {
+				// A field of an embedding struct with the same key wins, as in
+				// Go's field promotion, so this one only gets its default
+				if !f.opts.DisableDefaults {
+					if err := f.setDefaultValues(field, info.field.Tag, joinPath(path, info.field.Name)); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if ok {
+				state.consumed[key] = true
+			}
+			fieldPath := joinPath(path, info.field.Name)
+			if !f.allowed(fieldPath) {
+				continue
+			}
+			err := f.fillStructField(field, info, inputMap, fieldPath, state)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fillEmbedded fills the fields promoted through an embedded struct. A nil
+// embedded pointer is only allocated when the input has a key for one of its
+// promoted fields, and every level of embedding counts towards MaxDepth, so
+// self-embedding types such as struct{ *T } can't recurse forever.
+func (f *filler) fillEmbedded(field reflect.Value, inputMap map[string]any, path string, state *structState) error {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			if !f.promotesKey(field.Type().Elem(), inputMap, path, state, make(map[reflect.Type]bool)) {
+				return nil
+			}
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		field = field.Elem()
+	}
+	if err := f.enter(path); err != nil {
+		return err
+	}
+	defer func() { f.depth-- }()
+	return f.fillFields(field, inputMap, path, state)
+}
+
+// promotesKey reports whether inputMap has a key that would be filled into a
+// field promoted through the embedded struct type t, skipping keys shadowed
+// by the embedding structs and fields outside the FillMasked mask.
+func (f *filler) promotesKey(t reflect.Type, inputMap map[string]any, path string, state *structState, visited map[reflect.Type]bool) bool {
+	if visited[t] {
+		return false
+	}
+	visited[t] = true
+	fields := cachedFields(t)
+	for i := range fields {
+		info := &fields[i]
+		if info.embedded || info.skip || info.remaining {
+			continue
+		}
+		key, ok := f.inputKey(info, state)
+		if info.embeddedInterface {
+			key, ok = f.discriminator(info), true
+		}
+		if !ok || state.shadowed[key] || !f.allowed(joinPath(path, info.field.Name)) {
+			continue
+		}
+		if _, present := inputMap[key]; present {
+			return true
+		}
+	}
+
+	outer := state.shadowed
+	state.shadowed = f.shadowedKeys(fields, state)
+	defer func() { state.shadowed = outer }()
+	for i := range fields {
+		if !fields[i].embedded {
+			continue
+		}
+		embeddedType := fields[i].field.Type
+		if embeddedType.Kind() == reflect.Ptr {
+			embeddedType = embeddedType.Elem()
+		}
+		if f.promotesKey(embeddedType, inputMap, path, state, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// reportUnexported warns about each input key that matches an unexported
+// field of structType, since such fields are silently left unfilled.
+func (f *filler) reportUnexported(structType reflect.Type, inputMap map[string]any, path string, state *structState) {
+	fields := cachedUnexportedFields(structType)
+	for i := range fields {
+		info := &fields[i]
+		if info.skip {
+			continue
+		}
+		key, ok := f.inputKey(info, state)
+		if !ok || state.consumed[key] {
+			continue
+		}
+		if _, present := inputMap[key]; present {
+			f.warnf("warning: input key %s matches unexported field %s, skipping", key, joinPath(path, info.field.Name))
+		}
+	}
+}
+
+// shadowedKeys returns the keys that fields embedded in a struct with the
+// given fields must not be filled from: the keys of its own fields and those
+// of the structs that embed it.
+func (f *filler) shadowedKeys(fields []fieldInfo, state *structState) map[string]bool {
+	var shadowed map[string]bool
+	for i := range fields {
+		if fields[i].embedded || fields[i].embeddedInterface || fields[i].remaining {
+			continue
+		}
+		key, ok := f.inputKey(&fields[i], state)
+		if !ok {
+			continue
+		}
+		if shadowed == nil {
+			// Only copy the outer keys once this struct adds some of its own
+			shadowed = make(map[string]bool, len(fields)+len(state.shadowed))
+			for outer := range state.shadowed {
+				shadowed[outer] = true
+			}
+		}
+		shadowed[key] = true
+	}
+	if shadowed == nil {
+		return state.shadowed
+	}
+	return shadowed
+}
+
+// fillEmbeddedInterface resolves an embedded interface field through the type
+// registry using the discriminator in the struct's own input map, then fills
+// the concrete value from that same map as if its fields were promoted. The
+// field is left nil when the discriminator is absent.
+func (f *filler) fillEmbeddedInterface(field reflect.Value, info *fieldInfo, inputMap map[string]any, path string, state *structState) error {
+	fieldPath := joinPath(path, info.field.Name)
+	discriminator := f.discriminator(info)
+	state.consumed[discriminator] = true
+	if _, ok := inputMap[discriminator]; !ok {
+		return nil
+	}
+	typeIdentifier, ok := inputMap[discriminator].(string)
+	if !ok {
+		return wrapFieldError(fieldPath, fmt.Errorf("type identifier missing for embedded interface field %s", info.field.Name))
+	}
+	if len(f.opts.TypeRegistry) == 0 {
+		return wrapFieldError(fieldPath, fmt.Errorf("no type registry provided for embedded interface field %s", info.field.Name))
+	}
+	constructor := f.opts.TypeRegistry[typeIdentifier]
+	if constructor == nil {
+		return wrapFieldError(fieldPath, fmt.Errorf("type identifier %s not found in type registry for field %s", typeIdentifier, info.field.Name))
+	}
+
+	newInstanceValue := reflect.ValueOf(constructor())
+	if !newInstanceValue.Type().AssignableTo(field.Type()) {
+		return wrapFieldError(fieldPath, fmt.Errorf("type %v does not implement %v for field %s", newInstanceValue.Type(), field.Type(), info.field.Name))
+	}
+	if newInstanceValue.Kind() != reflect.Ptr || newInstanceValue.Elem().Kind() != reflect.Struct {
+		return wrapFieldError(fieldPath, fmt.Errorf("type %v for embedded interface field %s must be a pointer to a struct", newInstanceValue.Type(), info.field.Name))
+	}
+	// Promoted fields keep the path of the embedding struct
+	if err := f.fillFields(newInstanceValue.Elem(), inputMap, path, state); err != nil {
+		return err
+	}
+	field.Set(newInstanceValue)
+	return nil
+}
+
+// collectRemaining stores every input key not matched by another field in the
+// struct's `fill:",remaining"` map field and marks them as consumed.
+func collectRemaining(state *structState, inputMap map[string]any, path string) error {
+	fieldName := state.remainingInfo.field.Name
+	if state.remaining.Type() != reflect.TypeOf(map[string]any{}) {
+		return wrapFieldError(joinPath(path, fieldName), fmt.Errorf("field %s tagged remaining must be of type map[string]any", fieldName))
+	}
+
+	remaining := make(map[string]any)
+	for key, value := range inputMap {
+		if !state.consumed[key] {
+			remaining[key] = value
+			state.consumed[key] = true
+		}
+	}
+	if len(remaining) > 0 {
+		state.remaining.Set(reflect.ValueOf(remaining))
+	}
+	return nil
+}
+
+// checkUnknownKeys returns an error listing the keys of inputMap that did not
+// match any field.
+func checkUnknownKeys(inputMap map[string]any, consumed map[string]bool, path string) error {
+	var unknown []string
+	for key := range inputMap {
+		if !consumed[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	err := fmt.Errorf("unknown keys in input map: %s", strings.Join(unknown, ", "))
+	if path == "" {
+		return err
+	}
+	return wrapFieldError(path, err)
+}
+
+func (f *filler) fillStructField(field reflect.Value, info *fieldInfo, inputMap map[string]any, path string, state *structState) error {
+	var inputValue any
+	key, ok := f.inputKey(info, state)
+	if ok {
+		inputValue, ok = inputMap[key]
+	}
+	if info.rulesErr != nil {
+		return wrapFieldError(path, info.rulesErr)
+	}
+	if !ok && f.mask != nil {
+		return nil // Partial updates leave fields without a key as they are
+	}
+	if f.zeroOnly && !field.IsZero() {
+		return nil
+	}
+	if err := validateRequired(info.rules, info.field.Name, inputValue, ok); err != nil {
+		return wrapFieldError(path, setValidationField(err, info.field.Name))
+	}
+	if !ok || inputValue == nil {
+		// Field name not in map, or null, set default value if specified
+		if !f.opts.DisableDefaults {
+			return f.setDefaultValues(field, info.field.Tag, path)
+		}
+		return nil // Skip further processing
+	}
+	// Nested fields are tracked while the value is set, so insert the path
+	// ahead of them once the set succeeds
+	tracked := len(f.filled)
+	if fillFunc := f.opts.FillFuncs[field.Type()]; fillFunc != nil {
+		if err := fillFunc(field.Addr().Interface(), inputValue); err != nil {
+			return wrapFieldError(path, err)
+		}
+		f.filled = slices.Insert(f.filled, tracked, path)
+		return nil
+	}
+	if err := f.setFieldValue(field, info, inputValue, path); err != nil {
+		if f.opts.SkipUnsupported && errors.Is(err, ErrUnsupportedType) {
+			f.warnf("warning: field %s has unsupported type %v, skipping", path, field.Type())
+			f.filled = f.filled[:tracked] // Skipped fields weren't filled from the input
+			return nil
+		}
+		return wrapFieldError(path, setValidationField(err, info.field.Name))
+	}
+	f.filled = slices.Insert(f.filled, tracked, path)
+	return nil
+}
+
+// setFieldValue converts inputValue into field. The first handler that applies
+// wins: a registered Converter, time.Time, time.Duration, a TextUnmarshaler
+// given a string, a Fillable given a map, an sql.Scanner given anything but a
+// map, a nested struct, a Set(string) method, and finally the field's kind.
+func (f *filler) setFieldValue(field reflect.Value, info *fieldInfo, inputValue any, path string) error {
+	fieldName := info.field.Name
+	tag := info.field.Tag
+	rules, elemRules := info.rules, info.elemRules
+
+	if converter := f.opts.Converters[field.Type()]; converter != nil {
+		converted, err := converter(inputValue, field.Type())
+		if err != nil {
+			return err
+		}
+		convertedValue := reflect.ValueOf(converted)
+		if !convertedValue.IsValid() || !convertedValue.Type().ConvertibleTo(field.Type()) {
+			return fmt.Errorf("converter for field %s returned %T, expected %v", fieldName, converted, field.Type())
+		}
+		field.Set(convertedValue.Convert(field.Type()))
+		return nil
+	}
+
+	if field.Type() == timeType {
+		if timeVal, ok := inputValue.(time.Time); ok {
+			field.Set(reflect.ValueOf(timeVal))
+			return nil
+		}
+		// Parse timestamps from strings, using the format tag as the layout if present
+		inputStr, ok := inputValue.(string)
+		if !ok {
+			return fmt.Errorf("invalid type for field %s, expected string for time.Time", fieldName)
+		}
+		timeVal, err := parseTime(inputStr, f.timeLayouts(tag))
+		if err != nil {
+			return fmt.Errorf("invalid time for field %s, %v", fieldName, err)
+		}
+		field.Set(reflect.ValueOf(timeVal))
+		return nil
+	}
+
+	if field.Type() == durationType {
+		// Durations accept Go duration strings ("30s") or plain nanosecond counts
+		inputStr, ok := inputValue.(string)
+		if !ok {
+			// Numbers are parsed like int64 fields, so 1e+09 from JSON is 1s
+			nanos, err := f.parseInt(inputValue, durationType)
+			if err != nil {
+				return fmt.Errorf("invalid duration %v for field %s: %v", inputValue, fieldName, err)
+			}
+			field.SetInt(nanos)
+			return nil
+		}
+		durationVal, err := time.ParseDuration(inputStr)
+		if err != nil {
+			nanos, intErr := strconv.ParseInt(inputStr, 10, 64)
+			if intErr != nil {
+				return fmt.Errorf("invalid duration %q for field %s", inputStr, fieldName)
+			}
+			durationVal = time.Duration(nanos)
+		}
+		field.SetInt(int64(durationVal))
+		return nil
+	}
+
+	if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		// Let types that know how to parse themselves do so from string input
+		if inputStr, ok := inputValue.(string); ok {
+			if err := unmarshaler.UnmarshalText([]byte(inputStr)); err != nil {
+				return fmt.Errorf("invalid value for field %s: %v", fieldName, err)
+			}
+			return nil
+		}
+	}
+
+	if fillable, ok := field.Addr().Interface().(Fillable); ok {
+		// Let types that know how to fill themselves do so from map input
+		if nestedMap, ok := stringKeyMap(inputValue); ok {
+			return fillable.Fill(nestedMap)
+		}
+	}
+
+	if scanner, ok := field.Addr().Interface().(sql.Scanner); ok {
+		// Database types such as sql.NullString scan scalars and set Valid
+		if _, isMap := stringKeyMap(inputValue); !isMap {
+			if err := scanner.Scan(inputValue); err != nil {
+				return fmt.Errorf("invalid value for field %s: %v", fieldName, err)
+			}
+			return nil
+		}
+	}
+
+	if field.Kind() == reflect.Struct {
+		// Handle nested (non-embedded) structs
+		nestedMap, ok := stringKeyMap(inputValue)
+		if !ok {
+			return fmt.Errorf("invalid type for field %s, expected map[string]any for nested struct", fieldName)
+		}
+		return f.fill(field.Addr().Interface(), nestedMap, path)
+	}
+
+	// Check for and call the Set method if it exists
+	setter := field.Addr().MethodByName("Set")
+	if setter.IsValid() && setter.Type().NumIn() == 1 && setter.Type().In(0).Kind() == reflect.String {
+		inputStr, ok := inputValue.(string)
+		if !ok {
+			return fmt.Errorf("expected string for field %s with Set method", fieldName)
+		}
+		errValues := setter.Call([]reflect.Value{reflect.ValueOf(inputStr)})
+		if len(errValues) == 1 && !errValues[0].IsNil() { // assuming Set method returns an error
+			return errValues[0].Interface().(error)
+		}
+		return nil
+	}
+
+	if _, ok := inputValue.(json.Number); ok && (field.Kind() == reflect.String || field.Kind() == reflect.Bool) {
+		return fmt.Errorf("invalid type for field %s, expected %v but got json.Number", fieldName, field.Kind())
+	}
+	if f.opts.StrictTypes && !matchesKind(inputValue, field.Kind()) {
+		return fmt.Errorf("invalid type for field %s, expected %v but got %T", fieldName, field.Kind(), inputValue)
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		val, ok := inputValue.(string)
+		if !ok {
+			return fmt.Errorf("invalid type for field %s, expected string but got %T", fieldName, inputValue)
+		}
+		if f.opts.TrimSpace || info.trim {
+			// Trim before validating so padding doesn't count towards length
+			val = strings.TrimSpace(val)
+		}
+		if err := validateStringField(rules, val, f.opts.Validators); err != nil {
+			return err
+		}
+		field.SetString(val)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		intVal, err := f.parseInt(inputValue, field.Type())
+		if err != nil {
+			return err
+		}
+		if err := validateIntField(rules, intVal, f.opts.Validators); err != nil {
+			return err
+		}
+		field.SetInt(intVal)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		inputStr := fmt.Sprintf("%v", inputValue)
+		if strings.HasPrefix(inputStr, "-") {
+			return fmt.Errorf("invalid value %s for field %s, expected a non-negative integer", inputStr, fieldName)
+		}
+		uintVal, err := f.parseUint(inputValue, field.Type())
+		if err != nil {
+			return err
+		}
+		if err := validateUintField(rules, uintVal, f.opts.Validators); err != nil {
+			return err
+		}
+		field.SetUint(uintVal)
+	case reflect.Bool:
+		boolVal, err := f.parseBool(fmt.Sprintf("%v", inputValue))
+		if err != nil {
+			return err
+		}
+		if err := validateBoolField(rules, boolVal, f.opts.Validators); err != nil {
+			return err
+		}
+		field.SetBool(boolVal)
+	case reflect.Float32, reflect.Float64:
+		floatVal, err := parseFloat(inputValue, field.Type())
+		if err != nil {
+			return err
+		}
+		if err := validateFloatField(rules, floatVal, f.opts.Validators); err != nil {
+			return err
+		}
+		field.SetFloat(floatVal)
+	case reflect.Ptr:
+		if inputValue == nil {
+			// Explicit nil leaves the pointer unset
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		if f.mask != nil && !field.IsNil() && field.Elem().Kind() == reflect.Struct {
+			// Partial updates keep the fields of the struct already pointed to
+			return f.setFieldValue(field.Elem(), info, inputValue, path)
+		}
+		// Allocate the pointed-to value and fill it like a regular field
+		ptr := reflect.New(field.Type().Elem())
+		if err := f.setFieldValue(ptr.Elem(), info, inputValue, path); err != nil {
+			return err
+		}
+		field.Set(ptr)
+	case reflect.Interface:
+		if field.NumMethod() == 0 {
+			// Empty interfaces such as any hold the input as it is
+			field.Set(reflect.ValueOf(inputValue))
+			return nil
+		}
+		// Resolve the concrete type through the type registry
+		elemMap, ok := stringKeyMap(inputValue)
+		if !ok {
+			return fmt.Errorf("invalid type for field %s, expected map[string]any for interface", fieldName)
+		}
+		discriminator := f.discriminator(info)
+		var newInstance any
+		if resolve := f.opts.TypeResolvers[field.Type()]; resolve != nil {
+			resolved, err := resolve(elemMap)
+			if err != nil {
+				return err
+			}
+			newInstance = resolved
+		} else {
+			typeIdentifier, ok := elemMap[discriminator].(string)
+			if !ok {
+				return fmt.Errorf("type identifier missing for interface field %s", fieldName)
+			}
+			constructor := f.opts.TypeRegistry[typeIdentifier]
+			if constructor == nil {
+				return fmt.Errorf("type identifier %s not found in type registry for field %s", typeIdentifier, fieldName)
+			}
+			newInstance = constructor()
+		}
+		if err := f.fill(newInstance, elemMap, path, discriminator); err != nil {
+			return err
+		}
+		newInstanceValue := reflect.ValueOf(newInstance)
+		if !newInstanceValue.Type().AssignableTo(field.Type()) {
+			return fmt.Errorf("type %v does not implement %v for field %s", newInstanceValue.Type(), field.Type(), fieldName)
+		}
+		field.Set(newInstanceValue)
+	case reflect.Slice:
+		if inputStr, ok := inputValue.(string); ok && field.Type().Elem().Kind() == reflect.Uint8 {
+			// Byte slices are decoded from strings according to the format tag
+			bytes, err := decodeBytes(inputStr, tag.Get("format"))
+			if err != nil {
+				return fmt.Errorf("invalid bytes for field %s: %v", fieldName, err)
+			}
+			field.SetBytes(bytes)
+			if err := validateSliceField(rules, field.Len()); err != nil {
+				return err
+			}
+			return nil
+		}
+		inputValueReflect := reflect.ValueOf(inputValue)
+		if inputValueReflect.Kind() != reflect.Slice && f.opts.WrapScalars {
+			// Treat a single value as a one-element slice
+			inputValueReflect = reflect.ValueOf([]any{inputValue})
+		}
+		if inputValueReflect.Kind() != reflect.Slice {
+			return fmt.Errorf("invalid type for field %s, expected slice", fieldName)
+		}
+
+		sliceType := field.Type().Elem()
+
+		if sliceType.Kind() == reflect.Interface && sliceType.NumMethod() > 0 {
+			// Handle slices of interfaces differently
+			var dynamicSlice reflect.Value
+			discriminator := f.discriminator(info)
+
+			for j := 0; j < inputValueReflect.Len(); j++ {
+				elemPath := indexPath(path, j)
+				elemMap, ok := stringKeyMap(inputValueReflect.Index(j).Interface())
+				if !ok {
+					return wrapFieldError(elemPath, fmt.Errorf("expected map for interface slice element"))
+				}
+
+				var newInstance any
+				if resolve := f.opts.TypeResolvers[sliceType]; resolve != nil {
+					// The resolver picks the type from the element's content
+					resolved, err := resolve(elemMap)
+					if err != nil {
+						return wrapFieldError(elemPath, err)
+					}
+					newInstance = resolved
+				} else {
+					typeIdentifier, ok := elemMap[discriminator].(string)
+					if !ok {
+						return wrapFieldError(elemPath, fmt.Errorf("type identifier missing for interface slice element"))
+					}
+					constructor := f.opts.TypeRegistry[typeIdentifier]
+					if constructor == nil && f.opts.DisallowUnknownTypes {
+						return wrapFieldError(elemPath, fmt.Errorf("type identifier %s not found in type registry for field %s", typeIdentifier, fieldName))
+					}
+					if constructor == nil {
+						f.warnf("warning: type identifier %s not found in type registry, skipping", typeIdentifier)
+						continue // Skip this element
+					}
+					newInstance = constructor() // Instantiate new type
+				}
+
+				err := f.fill(newInstance, elemMap, elemPath, discriminator) // Recursive call to fill the new instance
+				if err != nil {
+					return wrapFieldError(elemPath, err)
+				}
+
+				if !dynamicSlice.IsValid() {
+					dynamicSlice = reflect.MakeSlice(field.Type(), 0, inputValueReflect.Len())
+				}
+
+				// Store the pointer itself so pointer-receiver methods stay in the method set
+				newInstanceValue := reflect.ValueOf(newInstance)
+				if !newInstanceValue.Type().AssignableTo(sliceType) {
+					return wrapFieldError(elemPath, fmt.Errorf("type %v does not implement %v for field %s", newInstanceValue.Type(), sliceType, fieldName))
+				}
+				dynamicSlice = reflect.Append(dynamicSlice, newInstanceValue)
+			}
+
+			if dynamicSlice.IsValid() {
+				f.setSlice(field, dynamicSlice)
+			}
+		} else {
+			// Handle slices of primitives and structs as before
+			slice := reflect.MakeSlice(field.Type(), inputValueReflect.Len(), inputValueReflect.Len())
+			for j := 0; j < inputValueReflect.Len(); j++ {
+				err := f.setElement(slice.Index(j), inputValueReflect.Index(j), info, path, j)
+				if err != nil {
+					return err
+				}
+			}
+			f.setSlice(field, slice)
+		}
+		if err := validateSliceField(rules, field.Len()); err != nil {
+			return err
+		}
+	case reflect.Array:
+		inputValueReflect := reflect.ValueOf(inputValue)
+		if inputValueReflect.Kind() != reflect.Slice && inputValueReflect.Kind() != reflect.Array {
+			return fmt.Errorf("invalid type for field %s, expected slice or array", fieldName)
+		}
+		length := inputValueReflect.Len()
+		if length > field.Len() {
+			if !f.opts.TruncateArrays {
+				return fmt.Errorf("too many elements for field %s: got %d, array holds %d", fieldName, length, field.Len())
+			}
+			length = field.Len()
+		}
+
+		// Elements beyond the input are left at their zero value
+		array := reflect.New(field.Type()).Elem()
+		for j := 0; j < length; j++ {
+			err := f.setElement(array.Index(j), inputValueReflect.Index(j), info, path, j)
+			if err != nil {
+				return err
+			}
+		}
+		field.Set(array)
+	case reflect.Map:
+		inputMapReflectValue := reflect.ValueOf(inputValue)
+		if inputMapReflectValue.Kind() != reflect.Map {
+			return fmt.Errorf("invalid type for field %s, expected a map", fieldName)
+		}
+		// Rules on a map field, before or after dive, apply to each value
+		valueRules := append(rules[:len(rules):len(rules)], elemRules...)
+		return f.setMap(field, inputMapReflectValue, info, valueRules, path)
+	default:
+		return fmt.Errorf("%w: %v", ErrUnsupportedType, field.Kind())
+	}
+	return nil
+}
+
+// fieldKey returns the input map key for a field: the name from its fill tag
+// (e.g. `fill:"user_id"`) if present, then the name from its json tag if
+// Options.UseJSONTags is set, otherwise a key derived from the field name
+// according to the KeyMatching option. It reports false if the field cannot
+// be matched to any key.
+func (f *filler) fieldKey(info *fieldInfo) (string, bool) {
+	if info.fillName != "" && !info.skip {
+		return info.fillName, true
+	}
+	if f.opts.UseJSONTags {
+		if info.jsonName == "-" {
+			return "", false
+		}
+		if info.jsonName != "" {
+			return info.jsonName, true
+		}
+	}
+	if f.opts.KeyFunc != nil {
+		return f.opts.KeyFunc(info.field.Name), true
+	}
+	switch f.opts.KeyMatching {
+	case MatchExact:
+		return info.field.Name, true
+	case MatchTag:
+		return "", false
+	default:
+		return info.lowerName, true
+	}
+}
+
+// inputKey is like fieldKey but, with MatchCaseInsensitive, returns the key as
+// it is spelled in the input map.
+func (f *filler) inputKey(info *fieldInfo, state *structState) (string, bool) {
+	key, ok := f.fieldKey(info)
+	if ok && state.keyIndex != nil {
+		if inputKey, found := state.keyIndex[strings.ToLower(key)]; found {
+			return inputKey, true
+		}
+	}
+	return key, ok
+}
+
+// parseFillTag splits a fill tag into the key name and any comma-separated
+// options that follow it, in the style of encoding/json.
+func parseFillTag(tag reflect.StructTag) (string, []string) {
+	fillTag := tag.Get("fill")
+	if fillTag == "" {
+		return "", nil
+	}
+	parts := strings.Split(fillTag, ",")
+	return parts[0], parts[1:]
+}
+
+// parseInt converts an input value for a signed integer field of type t.
+// Floats, as produced for every number by encoding/json, are truncated
+// towards zero unless Options.DisallowTruncation is set. Bools become 1 or 0
+// when Options.CoerceBools is set.
+func (f *filler) parseInt(inputValue any, t reflect.Type) (int64, error) {
+	if boolVal, ok := inputValue.(bool); ok && f.opts.CoerceBools {
+		return int64(boolToInt(boolVal)), nil
+	}
+	floatVal, ok := floatInput(inputValue)
+	if inputStr, isStr := inputValue.(string); isStr && !ok && f.opts.LenientInts {
+		inputValue, floatVal, ok = lenientInt(inputStr)
+	}
+	if !ok {
+		inputStr := fmt.Sprintf("%v", inputValue)
+		intVal, err := strconv.ParseInt(inputStr, 10, t.Bits())
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, overflowError(inputStr, t)
+		}
+		return intVal, err
+	}
+	truncated, err := f.truncate(floatVal)
+	if err != nil {
+		return 0, err
+	}
+	limit := math.Ldexp(1, t.Bits()-1)
+	if truncated < -limit || truncated >= limit {
+		return 0, overflowError(fmt.Sprintf("%v", floatVal), t)
+	}
+	return int64(truncated), nil
+}
+
+// parseUint is the unsigned counterpart of parseInt.
+func (f *filler) parseUint(inputValue any, t reflect.Type) (uint64, error) {
+	if boolVal, ok := inputValue.(bool); ok && f.opts.CoerceBools {
+		return uint64(boolToInt(boolVal)), nil
+	}
+	floatVal, ok := floatInput(inputValue)
+	if inputStr, isStr := inputValue.(string); isStr && !ok && f.opts.LenientInts {
+		inputValue, floatVal, ok = lenientInt(inputStr)
+	}
+	if !ok {
+		inputStr := fmt.Sprintf("%v", inputValue)
+		uintVal, err := strconv.ParseUint(inputStr, 10, t.Bits())
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, overflowError(inputStr, t)
+		}
+		return uintVal, err
+	}
+	truncated, err := f.truncate(floatVal)
+	if err != nil {
+		return 0, err
+	}
+	if truncated < 0 || truncated >= math.Ldexp(1, t.Bits()) {
+		return 0, overflowError(fmt.Sprintf("%v", floatVal), t)
+	}
+	return uint64(truncated), nil
+}
+
+// parseFloat converts a float input for a field of the float type t. Float
+// inputs are used as they are rather than formatted and parsed again, with a
+// range check for float32 fields.
+func parseFloat(inputValue any, t reflect.Type) (float64, error) {
+	floatVal, ok := floatInput(inputValue)
+	if !ok {
+		return strconv.ParseFloat(fmt.Sprintf("%v", inputValue), t.Bits())
+	}
+	if t.Bits() == 32 && !math.IsInf(floatVal, 0) && math.Abs(floatVal) > math.MaxFloat32 {
+		return 0, fmt.Errorf("value %v overflows float32 (max %v)", floatVal, math.MaxFloat32)
+	}
+	return floatVal, nil
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// warnf reports a non-fatal problem through Options.Logger, or the standard
+// logger when none is set.
+func (f *filler) warnf(format string, args ...any) {
+	if f.opts.Logger != nil {
+		f.opts.Logger(format, args...)
+		return
+	}
+	log.Printf(format, args...)
+}
+
+// parseBool parses a boolean input, additionally accepting yes/no, on/off and
+// enabled/disabled in any case when Options.LenientBools is set.
+func (f *filler) parseBool(inputStr string) (bool, error) {
+	if boolVal, err := strconv.ParseBool(inputStr); err == nil {
+		return boolVal, nil
+	}
+	if !f.opts.LenientBools {
+		return false, fmt.Errorf("invalid boolean %q, accepted values are 1, t, T, TRUE, true, True, 0, f, F, FALSE, false, False", inputStr)
+	}
+	switch strings.ToLower(inputStr) {
+	case "true", "t", "yes", "y", "on", "enabled":
+		return true, nil
+	case "false", "f", "no", "n", "off", "disabled":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q, accepted values are 1/0, true/false, t/f, yes/no, y/n, on/off and enabled/disabled (case-insensitive)", inputStr)
+}
+
+// overflowError reports that value does not fit in the integer type t,
+// naming the limit it crossed, e.g. "value 300 overflows int8 (max 127)".
+func overflowError(value string, t reflect.Type) error {
+	bits := uint(t.Bits())
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Errorf("value %s overflows %v (max %d)", value, t.Kind(), uint64(math.MaxUint64)>>(64-bits))
+	}
+	if strings.HasPrefix(value, "-") {
+		return fmt.Errorf("value %s overflows %v (min %d)", value, t.Kind(), int64(-1)<<(bits-1))
+	}
+	return fmt.Errorf("value %s overflows %v (max %d)", value, t.Kind(), int64(math.MaxInt64)>>(64-bits))
+}
+
+// truncate drops the fractional part of a float destined for an integer field.
+// StrictTypes implies DisallowTruncation.
+func (f *filler) truncate(floatVal float64) (float64, error) {
+	if math.IsNaN(floatVal) || math.IsInf(floatVal, 0) {
+		return 0, fmt.Errorf("value %v is not a finite number", floatVal)
+	}
+	truncated := math.Trunc(floatVal)
+	if truncated != floatVal && (f.opts.DisallowTruncation || f.opts.StrictTypes) {
+		return 0, fmt.Errorf("value %v has a fractional part", floatVal)
+	}
+	return truncated, nil
+}
+
+// floatInput reports whether inputValue is a float and returns it as a
+// float64. A json.Number counts as a float only if it has a fraction or an
+// exponent; integers are parsed from its string so they keep full precision.
+func floatInput(inputValue any) (float64, bool) {
+	switch v := inputValue.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case json.Number:
+		if strings.ContainsAny(string(v), ".eE") {
+			floatVal, err := v.Float64()
+			return floatVal, err == nil
+		}
+	}
+	return 0, false
+}
+
+// lenientInt rewrites an integer string for Options.LenientInts. Digit
+// separators such as "1_000" are removed, and a string with an exponent such
+// as "1e3" is parsed as a float, reported by the bool, to be truncated like
+// float input. Malformed strings are returned unchanged to fail parsing.
+func lenientInt(s string) (string, float64, bool) {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '_' && (i == 0 || i == len(s)-1 || !isDigit(s[i-1]) || !isDigit(s[i+1])) {
+			return s, 0, false // Separators must sit between two digits
+		}
+	}
+	s = strings.ReplaceAll(s, "_", "")
+	if strings.ContainsAny(s, "eE") {
+		if floatVal, err := strconv.ParseFloat(s, 64); err == nil {
+			return s, floatVal, true
+		}
 	}
-	structVal = structVal.Elem()
-	structTypeVal := structVal.Type()
+	return s, 0, false
+}
 
-	for i := 0; i < structVal.NumField(); i++ {
-		field := structVal.Field(i)
-		fieldType := structTypeVal.Field(i)
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
 
-		if !field.CanSet() {
-			continue
+// tagOption looks up an option from a field's fill tag. For key=value options
+// such as `fill:",discriminator=kind"` it returns the value.
+func tagOption(opts []string, option string) (string, bool) {
+	for _, opt := range opts {
+		if opt == option {
+			return "", true
 		}
-
-		if fieldType.Anonymous && field.Kind() == reflect.Struct {
-			// Recursively fill embedded structs
-			err := Fill(field.Addr().Interface(), inputMap, typeRegistry)
-			if err != nil {
-				return err
-			}
-		} else {
-			err := fillStructField(field, fieldType, inputMap, typeRegistry)
-			if err != nil {
-				return err
-			}
+		if value, ok := strings.CutPrefix(opt, option+"="); ok {
+			return value, true
 		}
 	}
-	return nil
+	return "", false
 }
 
-func fillStructField(field reflect.Value, fieldType reflect.StructField, inputMap map[string]any, typeRegistry map[string]func() any) error {
-	fieldName := fieldType.Name
-	tag := fieldType.Tag
-	inputValue, ok := inputMap[strings.ToLower(fieldName)]
+// timeLayouts returns the layouts tried for a time.Time field: those in its
+// format tag, separated by "|", then Options.TimeLayouts, then RFC3339.
+func (f *filler) timeLayouts(tag reflect.StructTag) []string {
+	if format := tag.Get("format"); format != "" {
+		return strings.Split(format, "|")
+	}
+	if len(f.opts.TimeLayouts) > 0 {
+		return f.opts.TimeLayouts
+	}
+	return []string{time.RFC3339}
+}
 
-	if field.Kind() == reflect.Struct && !fieldType.Anonymous {
-		// Handle nested (non-embedded) structs
-		if ok {
-			nestedMap, ok := inputValue.(map[string]any)
-			if !ok {
-				return fmt.Errorf("invalid type for field %s, expected map[string]any for nested struct", fieldName)
-			}
-			err := Fill(field.Addr().Interface(), nestedMap, typeRegistry)
-			if err != nil {
-				return err
-			}
-		} else {
-			// Set default values for nested structs if not in input map
-			setDefaultValues(field, tag)
+// parseTime parses value with the first of layouts that accepts it.
+func parseTime(value string, layouts []string) (time.Time, error) {
+	var err error
+	for _, layout := range layouts {
+		var timeVal time.Time
+		if timeVal, err = time.Parse(layout, value); err == nil {
+			return timeVal, nil
 		}
-		return nil
 	}
+	if len(layouts) == 1 {
+		return time.Time{}, fmt.Errorf("expected layout %q: %v", layouts[0], err)
+	}
+	quoted := make([]string, len(layouts))
+	for i, layout := range layouts {
+		quoted[i] = strconv.Quote(layout)
+	}
+	return time.Time{}, fmt.Errorf("expected one of layouts %s", strings.Join(quoted, ", "))
+}
 
-	if !ok {
-		// Field name not in map, set default value if specified
-		setDefaultValues(field, tag)
-		return nil // Skip further processing
+// decodeBytes decodes a string for a byte slice field. The format is
+// "base64" (standard encoding, the default), "hex" or "raw" for the string's
+// own bytes.
+func decodeBytes(value, format string) ([]byte, error) {
+	switch format {
+	case "", "base64":
+		return base64.StdEncoding.DecodeString(value)
+	case "hex":
+		return hex.DecodeString(value)
+	case "raw":
+		return []byte(value), nil
 	}
+	return nil, fmt.Errorf("unsupported format %q, expected base64, hex or raw", format)
+}
 
-	// Check for and call the Set method if it exists
-	setter := field.Addr().MethodByName("Set")
-	if setter.IsValid() && setter.Type().NumIn() == 1 && setter.Type().In(0).Kind() == reflect.String {
-		inputStr, ok := inputValue.(string)
-		if !ok {
-			return fmt.Errorf("expected string for field %s with Set method", fieldName)
+// stringKeyMap returns input as a map[string]any. Other maps with string keys,
+// such as map[string]string, are copied into one, as are maps with interface
+// keys such as the map[interface{}]interface{} decoded by gopkg.in/yaml.v2,
+// whose string, number and bool keys are formatted as strings.
+func stringKeyMap(input any) (map[string]any, bool) {
+	if inputMap, ok := input.(map[string]any); ok {
+		return inputMap, true
+	}
+	inputVal := reflect.ValueOf(input)
+	if inputVal.Kind() != reflect.Map {
+		return nil, false
+	}
+	keyKind := inputVal.Type().Key().Kind()
+	if keyKind != reflect.String && keyKind != reflect.Interface {
+		return nil, false
+	}
+	inputMap := make(map[string]any, inputVal.Len())
+	iter := inputVal.MapRange()
+	for iter.Next() {
+		key := iter.Key()
+		if key.Kind() == reflect.Interface {
+			key = key.Elem()
 		}
-		errValues := setter.Call([]reflect.Value{reflect.ValueOf(inputStr)})
-		if len(errValues) == 1 && !errValues[0].IsNil() { // assuming Set method returns an error
-			return errValues[0].Interface().(error)
+		switch {
+		case key.Kind() == reflect.String:
+			inputMap[key.String()] = iter.Value().Interface()
+		case key.Kind() == reflect.Bool || isNumberKind(key.Kind()):
+			inputMap[fmt.Sprint(key.Interface())] = iter.Value().Interface()
+		default:
+			return nil, false
 		}
-		return nil
 	}
+	return inputMap, true
+}
 
-	switch field.Kind() {
-	case reflect.String:
-		if val, ok := inputValue.(string); ok {
-			field.SetString(val)
+// discriminator returns the key that holds the type identifier of interface
+// elements: the field's discriminator tag option, then Options.Discriminator,
+// then "type".
+func (f *filler) discriminator(info *fieldInfo) string {
+	if key, ok := tagOption(info.fillOpts, "discriminator"); ok && key != "" {
+		return key
+	}
+	if f.opts.Discriminator != "" {
+		return f.opts.Discriminator
+	}
+	return "type"
+}
+
+// allowed reports whether the field at path may be written under FillMasked:
+// it or one of the structs containing it is in the mask, or the mask names a
+// field nested inside it. Fields of slice and map elements are always allowed,
+// since their container is only filled when it is allowed and is replaced as
+// a whole.
+func (f *filler) allowed(path string) bool {
+	if f.mask == nil || f.mask[path] || strings.Contains(path, "[") {
+		return true
+	}
+	for prefix := path; ; {
+		i := strings.LastIndexByte(prefix, '.')
+		if i < 0 {
+			break
+		}
+		prefix = prefix[:i]
+		if f.mask[prefix] {
+			return true
 		}
+	}
+	for masked := range f.mask {
+		if strings.HasPrefix(masked, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// joinPath appends a field name to a dotted field path.
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// indexPath appends a slice index to a field path.
+func indexPath(path string, index int) string {
+	return path + "[" + strconv.Itoa(index) + "]"
+}
+
+// keyPath appends a map key to a field path.
+func keyPath(path string, key reflect.Value) string {
+	return fmt.Sprintf("%s[%v]", path, key)
+}
+
+// setSlice stores a filled slice in field, replacing its contents or, with
+// Options.AppendSlices, appending to them.
+func (f *filler) setSlice(field, slice reflect.Value) {
+	if f.opts.AppendSlices {
+		slice = reflect.AppendSlice(field, slice)
+	}
+	field.Set(slice)
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// setNumber sets a numeric slice element or map value from a json.Number or
+// a value of a Go number kind, with the same range, sign and truncation
+// checks as numeric fields.
+func (f *filler) setNumber(target reflect.Value, number any) error {
+	switch target.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		intVal, err := strconv.ParseInt(fmt.Sprintf("%v", inputValue), 10, field.Type().Bits())
+		intVal, err := f.parseInt(number, target.Type())
 		if err != nil {
 			return err
 		}
-		if err := validateIntField(tag, intVal); err != nil {
-			return err
+		target.SetInt(intVal)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if inputStr := fmt.Sprintf("%v", number); strings.HasPrefix(inputStr, "-") {
+			return fmt.Errorf("invalid value %s, expected a non-negative integer", inputStr)
 		}
-		field.SetInt(intVal)
-	case reflect.Bool:
-		boolVal, err := strconv.ParseBool(fmt.Sprintf("%v", inputValue))
+		uintVal, err := f.parseUint(number, target.Type())
 		if err != nil {
 			return err
 		}
-		field.SetBool(boolVal)
-	case reflect.Float32, reflect.Float64:
-		floatVal, err := strconv.ParseFloat(fmt.Sprintf("%v", inputValue), field.Type().Bits())
+		target.SetUint(uintVal)
+	default:
+		floatVal, err := parseFloat(number, target.Type())
 		if err != nil {
 			return err
 		}
-		field.SetFloat(floatVal)
-	case reflect.Slice:
-		inputValueReflect := reflect.ValueOf(inputValue)
-		if inputValueReflect.Kind() != reflect.Slice {
-			return fmt.Errorf("invalid type for field %s, expected slice", fieldName)
-		}
-
-		sliceType := field.Type().Elem()
+		target.SetFloat(floatVal)
+	}
+	return nil
+}
 
-		if sliceType.Kind() == reflect.Interface {
-			// Handle slices of interfaces differently
-			var dynamicSlice reflect.Value
+// numberInput returns a json.Number or number value as the plain json.Number,
+// int64, uint64 or float64 that parseInt, parseUint and parseFloat expect.
+func numberInput(value reflect.Value) any {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return value.Uint()
+	case reflect.Float32, reflect.Float64:
+		return value.Float()
+	}
+	return value.Interface() // json.Number
+}
 
-			for j := 0; j < inputValueReflect.Len(); j++ {
-				elemMap, ok := inputValueReflect.Index(j).Interface().(map[string]any)
-				if !ok {
-					return fmt.Errorf("expected map for interface slice element")
-				}
+// matchesKind reports whether input has a Go type that fills a field of the
+// given kind without coercion under Options.StrictTypes. Kinds other than
+// strings, bools and numbers are not checked.
+func matchesKind(input any, kind reflect.Kind) bool {
+	inputKind := reflect.ValueOf(input).Kind()
+	switch {
+	case kind == reflect.String || kind == reflect.Bool:
+		return inputKind == kind
+	case isNumberKind(kind):
+		_, isNumber := input.(json.Number)
+		return isNumber || isNumberKind(inputKind)
+	}
+	return true
+}
 
-				typeIdentifier, ok := elemMap["type"].(string)
-				if !ok {
-					return fmt.Errorf("type identifier missing for interface slice element")
-				}
-				if typeRegistry[typeIdentifier] == nil {
-					log.Printf("warning: type identifier %s not found in type registry, skipping", typeIdentifier)
-					continue // Skip this element
-				}
+func isStructOrStructPtr(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
 
-				newInstance := typeRegistry[typeIdentifier]()   // Instantiate new type
-				err := Fill(newInstance, elemMap, typeRegistry) // Recursive call to fill the new instance
-				if err != nil {
-					return err
-				}
+// setMap fills the map field from inputMap, converting each key and value to
+// the map's types and validating each value against valueRules.
+func (f *filler) setMap(field reflect.Value, inputMap reflect.Value, info *fieldInfo, valueRules []rule, path string) error {
+	fieldName := info.field.Name
+	mapType := field.Type()
+	elemType := mapType.Elem()
+	newMap := reflect.MakeMapWithSize(mapType, inputMap.Len())
 
-				if !dynamicSlice.IsValid() {
-					dynamicSlice = reflect.MakeSlice(reflect.SliceOf(sliceType), 0, inputValueReflect.Len())
-				}
+	for _, key := range inputMap.MapKeys() {
+		val := inputMap.MapIndex(key)
+		if val.Kind() == reflect.Interface {
+			val = val.Elem()
+		}
 
-				newInstanceValue := reflect.ValueOf(newInstance)
-				dynamicSlice = reflect.Append(dynamicSlice, newInstanceValue)
-			}
+		// Convert key to the map's key type
+		convertedKey, err := convertMapKey(key, mapType.Key())
+		if err != nil {
+			return fmt.Errorf("invalid key for field %s: %w", fieldName, err)
+		}
 
-			if dynamicSlice.IsValid() {
-				field.Set(dynamicSlice)
+		// Convert value to the map's value type
+		var convertedVal reflect.Value
+		if val.IsValid() && !val.Type().ConvertibleTo(elemType) && isStructOrStructPtr(elemType) {
+			// Fill nested structs from their map the same way struct slices are
+			convertedVal = reflect.New(elemType).Elem()
+			if err := f.setFieldValue(convertedVal, info, val.Interface(), keyPath(path, key)); err != nil {
+				return wrapFieldError(keyPath(path, key), err)
 			}
 		} else {
-			// Handle slices of primitives and structs as before
-			slice := reflect.MakeSlice(reflect.SliceOf(sliceType), inputValueReflect.Len(), inputValueReflect.Cap())
-			for j := 0; j < inputValueReflect.Len(); j++ {
-				elem := inputValueReflect.Index(j)
-				elemKind := elem.Kind()
-				if elemKind == reflect.Interface {
-					elem = elem.Elem()
-					elemKind = elem.Kind()
-				}
-				if sliceType.Kind() == reflect.Struct && elemKind == reflect.Map {
-					nestedMap, ok := elem.Interface().(map[string]any)
-					if !ok {
-						return fmt.Errorf("invalid type for slice element in field %s, expected map[string]any for nested struct slice element", fieldName)
-					}
-					err := Fill(slice.Index(j).Addr().Interface(), nestedMap, typeRegistry)
-					if err != nil {
-						return err
-					}
-				} else {
-					// Convert each element to the correct type and set it in the slice
-					newValue, err := convertType(elem.Interface(), sliceType)
-					if err != nil {
-						return fmt.Errorf("error converting slice element for field %s: %v", fieldName, err)
-					}
-					slice.Index(j).Set(reflect.ValueOf(newValue))
-				}
+			convertedVal, err = f.convertValue(val, elemType)
+			if err != nil {
+				return wrapFieldError(keyPath(path, key), err)
 			}
-			field.Set(slice)
 		}
-	case reflect.Map:
-		inputMapReflectValue := reflect.ValueOf(inputValue)
-		if inputMapReflectValue.Kind() != reflect.Map {
-			return fmt.Errorf("invalid type for field %s, expected a map", fieldName)
+		if err := validateValue(valueRules, convertedVal, f.opts.Validators); err != nil {
+			return wrapFieldError(keyPath(path, key), err)
 		}
 
-		mapType := field.Type()
-		newMap := reflect.MakeMapWithSize(mapType, inputMapReflectValue.Len())
-
-		for _, key := range inputMapReflectValue.MapKeys() {
-			val := inputMapReflectValue.MapIndex(key)
-
-			// Convert key to the map's key type
-			convertedKey := key.Convert(mapType.Key())
+		newMap.SetMapIndex(convertedKey, convertedVal)
+	}
 
-			// Convert value to the map's value type
-			convertedVal := val.Convert(mapType.Elem())
+	field.Set(newMap)
+	return nil
+}
 
-			newMap.SetMapIndex(convertedKey, convertedVal)
+// setElement fills a single slice or array element from its input, filling
+// structs and maps from nested maps and converting everything else.
+func (f *filler) setElement(target reflect.Value, elem reflect.Value, info *fieldInfo, path string, index int) error {
+	fieldName, elemRules := info.field.Name, info.elemRules
+	elemType := target.Type()
+	if elem.Kind() == reflect.Interface {
+		elem = elem.Elem()
+	}
+	if !elem.IsValid() {
+		// Null elements are left at their zero value, as null map values are
+		return nil
+	}
+	if elemType.Kind() == reflect.Struct && elem.Kind() == reflect.Map {
+		nestedMap, ok := stringKeyMap(elem.Interface())
+		if !ok {
+			return wrapFieldError(indexPath(path, index), fmt.Errorf("invalid type for slice element in field %s, expected map[string]any for nested struct slice element", fieldName))
+		}
+		return f.fill(target.Addr().Interface(), nestedMap, indexPath(path, index))
+	}
+	if elemType.Kind() == reflect.Ptr && elemType.Elem().Kind() == reflect.Struct && elem.Kind() == reflect.Map {
+		// Allocate pointer elements and fill the struct they point to
+		target.Set(reflect.New(elemType.Elem()))
+		return f.setElement(target.Elem(), elem, info, path, index)
+	}
+	if elemType.Kind() == reflect.Map && elem.Kind() == reflect.Map && !elem.Type().ConvertibleTo(elemType) {
+		// Fill map elements key by key, converting each value to the element's value type
+		if err := f.setMap(target, elem, info, nil, indexPath(path, index)); err != nil {
+			return wrapFieldError(indexPath(path, index), err)
+		}
+		if err := validateValue(elemRules, target, f.opts.Validators); err != nil {
+			return wrapFieldError(indexPath(path, index), err)
 		}
+		return nil
+	}
 
-		field.Set(newMap)
-	default:
-		return fmt.Errorf("unsupported type: %v", field.Kind())
+	// Convert each element to the correct type and set it in the slice. The
+	// element path is only built when there is an error to report.
+	converted, err := f.convertValue(elem, elemType)
+	if err != nil {
+		return wrapFieldError(indexPath(path, index), fmt.Errorf("error converting slice element for field %s: %v", fieldName, err))
+	}
+	if !converted.Type().AssignableTo(elemType) {
+		// Guard Set against a conversion that produced a different named type
+		return wrapFieldError(indexPath(path, index), fmt.Errorf("error converting slice element for field %s: got %v, expected %v", fieldName, converted.Type(), elemType))
+	}
+	target.Set(converted)
+	if err := validateValue(elemRules, target, f.opts.Validators); err != nil {
+		return wrapFieldError(indexPath(path, index), err)
 	}
 	return nil
 }
@@ -247,82 +1689,300 @@ func setPrimitiveType(field reflect.Value, value any) bool {
 	return false
 }
 
-func validateIntField(tag reflect.StructTag, value int64) error {
-	validateTag := tag.Get("validate")
-	if validateTag == "" {
-		return nil // No validation rules
+// setDefaultValues applies the default tag to field. Slices accept "[]" for
+// an empty slice or comma-separated elements (`default:"a,b,c"`), and maps
+// accept "{}" for an empty map or comma-separated key:value pairs
+// (`default:"a:1,b:2"`). A delim tag replaces the comma for elements that
+// contain commas (`default:"a,b;c" delim:";"`). Struct fields accept a
+// JSON object that is filled into the struct like an input map
+// (`default:"{\"city\":\"Springfield\"}"`).
+// time.Time fields parse their default with the same layouts as input.
+// Without a default tag, a defaultfunc tag (`defaultfunc:"uuid"`) names a
+// generator in Options.DefaultFuncs that is called for the default instead.
+// Malformed defaults are ignored, but struct defaults that nest deeper than
+// Options.MaxDepth, as a self-referencing `default:"{}"` does, return
+// ErrMaxDepth.
+//
+// A field with an env tag (`env:"PORT"`) takes its default from that
+// environment variable when it is set and non-empty, so the precedence for a
+// field is: input map, then environment variable, then literal default, then
+// the zero value.
+func (f *filler) setDefaultValues(field reflect.Value, tag reflect.StructTag, path string) error {
+	// Direct default value setting for non-struct fields
+	defaultVal := tag.Get("default")
+	if envName := tag.Get("env"); envName != "" {
+		if envVal := os.Getenv(envName); envVal != "" {
+			defaultVal = envVal
+		}
 	}
-
-	rules := strings.Split(validateTag, ",")
-	for _, rule := range rules {
-		ruleParts := strings.SplitN(rule, "=", 2)
-		if len(ruleParts) != 2 {
-			return errors.New("invalid validate tag format")
+	if name := tag.Get("defaultfunc"); defaultVal == "" && name != "" {
+		if generate := f.opts.DefaultFuncs[name]; generate != nil && (!f.opts.DefaultsOnlyIfZero || field.IsZero()) {
+			f.setGenerated(field, generate())
+			return nil
 		}
-
-		ruleValue, err := strconv.ParseInt(ruleParts[1], 10, 64)
-		if err != nil {
-			return fmt.Errorf("invalid rule value: %v", err)
+	}
+	if defaultVal != "" && f.opts.DefaultsOnlyIfZero && !field.IsZero() {
+		// Keep the existing value, but still recurse so zero nested fields get defaults
+		defaultVal = ""
+	}
+	if defaultVal != "" && field.Type() == timeType {
+		// Defaults are parsed with the same layouts as input; malformed ones are ignored
+		if timeVal, err := parseTime(defaultVal, f.timeLayouts(tag)); err == nil {
+			field.Set(reflect.ValueOf(timeVal))
 		}
-
-		switch ruleParts[0] {
-		case "min":
-			if value < ruleValue {
-				return fmt.Errorf("value %d is less than min %d", value, ruleValue)
+		return nil
+	}
+	if defaultVal != "" {
+		switch field.Kind() {
+		case reflect.Slice:
+			setDefaultSlice(field, defaultVal, defaultDelim(tag))
+		case reflect.Map:
+			setDefaultMap(field, defaultVal, defaultDelim(tag))
+		case reflect.Ptr:
+			// Optional fields are only allocated when they have a default
+			ptr := reflect.New(field.Type().Elem())
+			if err := f.setDefaultValues(ptr.Elem(), tag, path); err != nil {
+				return err
 			}
-		case "max":
-			if value > ruleValue {
-				return fmt.Errorf("value %d is greater than max %d", value, ruleValue)
+			field.Set(ptr)
+		case reflect.Struct:
+			var defaultMap map[string]any
+			if err := json.Unmarshal([]byte(defaultVal), &defaultMap); err == nil {
+				// Use a separate filler so defaulted fields are not tracked as filled
+				defaults := &filler{opts: f.opts, depth: f.depth}
+				if err := defaults.fill(field.Addr().Interface(), defaultMap, path); errors.Is(err, ErrMaxDepth) {
+					return err
+				}
 			}
 		default:
-			return fmt.Errorf("unsupported validation rule: %s", ruleParts[0])
+			_ = setFromString(field, defaultVal)
+		}
+		return nil // Return after setting a direct default value
+	}
+
+	if f.opts.EmptyContainers && field.Kind() == reflect.Slice && field.IsNil() {
+		field.Set(reflect.MakeSlice(field.Type(), 0, 0))
+	}
+	if f.opts.EmptyContainers && field.Kind() == reflect.Map && field.IsNil() {
+		field.Set(reflect.MakeMap(field.Type()))
+	}
+
+	// Recursively set default values for nested structs, then let a
+	// Defaulter fill its keys as Fill does for an empty input map
+	if field.Kind() == reflect.Struct {
+		defaulter, hasDefaults := field.Addr().Interface().(Defaulter)
+		if hasDefaults && f.opts.DefaultsOnlyIfZero {
+			// As in fill, Defaults go first so they only see the fields' prior values
+			if err := f.applyDefaulter(field.Addr().Interface(), defaulter, nil, path, nil); err != nil {
+				return err
+			}
+		}
+		if err := f.setFieldDefaults(field, path); err != nil {
+			return err
+		}
+		if hasDefaults && !f.opts.DefaultsOnlyIfZero {
+			return f.applyDefaulter(field.Addr().Interface(), defaulter, nil, path, nil)
 		}
 	}
 	return nil
 }
 
-func setDefaultValues(field reflect.Value, tag reflect.StructTag) {
-	// Direct default value setting for non-struct fields
-	defaultVal := tag.Get("default")
-	if defaultVal != "" {
-		switch field.Kind() {
-		case reflect.String:
-			field.SetString(defaultVal)
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			intVal, err := strconv.ParseInt(defaultVal, 10, 64)
-			if err == nil {
-				field.SetInt(intVal)
-			}
-		case reflect.Bool:
-			boolVal, err := strconv.ParseBool(defaultVal)
-			if err == nil {
-				field.SetBool(boolVal)
+// setFieldDefaults applies setDefaultValues to each field of structVal. The
+// fields of embedded structs are expanded in place, and nil embedded pointers
+// are left nil as Fill leaves them.
+func (f *filler) setFieldDefaults(structVal reflect.Value, path string) error {
+	fields := cachedFields(structVal.Type())
+	for i := range fields {
+		if fields[i].skip {
+			continue
+		}
+		field := structVal.Field(fields[i].index)
+		if fields[i].embedded {
+			if field.Kind() == reflect.Ptr {
+				if field.IsNil() {
+					continue
+				}
+				field = field.Elem()
 			}
-		case reflect.Float32, reflect.Float64:
-			floatVal, err := strconv.ParseFloat(defaultVal, 64)
-			if err == nil {
-				field.SetFloat(floatVal)
+			// Promoted fields keep the path of the embedding struct
+			if err := f.setFieldDefaults(field, path); err != nil {
+				return err
 			}
+			continue
+		}
+		if err := f.setDefaultValues(field, fields[i].field.Tag, joinPath(path, fields[i].field.Name)); err != nil {
+			return err
 		}
-		return // Return after setting a direct default value
 	}
+	return nil
+}
 
-	// Recursively set default values for nested structs
-	if field.Kind() == reflect.Struct {
-		for i := 0; i < field.NumField(); i++ {
-			nestedField := field.Field(i)
-			nestedFieldType := field.Type().Field(i)
-			if nestedField.CanSet() {
-				setDefaultValues(nestedField, nestedFieldType.Tag)
-			}
+// setGenerated sets field to a value produced by a DefaultFuncs generator,
+// converting it to the field's type and allocating pointer fields as
+// needed. Values that can't be converted are ignored like malformed defaults.
+func (f *filler) setGenerated(field reflect.Value, generated any) {
+	converted, err := f.convertValue(reflect.ValueOf(generated), field.Type())
+	if err == nil {
+		field.Set(converted)
+		return
+	}
+	if field.Kind() == reflect.Ptr {
+		ptr := reflect.New(field.Type().Elem())
+		if converted, err := f.convertValue(reflect.ValueOf(generated), ptr.Elem().Type()); err == nil {
+			ptr.Elem().Set(converted)
+			field.Set(ptr)
+		}
+	}
+}
+
+// defaultDelim returns the separator between the elements of slice and map
+// defaults: the delim tag (`delim:";"`) if present, otherwise a comma.
+func defaultDelim(tag reflect.StructTag) string {
+	if delim := tag.Get("delim"); delim != "" {
+		return delim
+	}
+	return ","
+}
+
+func setDefaultSlice(field reflect.Value, defaultVal, delim string) {
+	if defaultVal == "[]" {
+		field.Set(reflect.MakeSlice(field.Type(), 0, 0))
+		return
+	}
+
+	parts := strings.Split(defaultVal, delim)
+	slice := reflect.MakeSlice(field.Type(), len(parts), len(parts))
+	for i, part := range parts {
+		if err := setFromString(slice.Index(i), part); err != nil {
+			return
+		}
+	}
+	field.Set(slice)
+}
+
+func setDefaultMap(field reflect.Value, defaultVal, delim string) {
+	mapType := field.Type()
+	if defaultVal == "{}" {
+		field.Set(reflect.MakeMap(mapType))
+		return
+	}
+
+	parts := strings.Split(defaultVal, delim)
+	newMap := reflect.MakeMapWithSize(mapType, len(parts))
+	for _, part := range parts {
+		pair := strings.SplitN(part, ":", 2)
+		if len(pair) != 2 {
+			return
+		}
+		key := reflect.New(mapType.Key()).Elem()
+		val := reflect.New(mapType.Elem()).Elem()
+		if setFromString(key, pair[0]) != nil || setFromString(val, pair[1]) != nil {
+			return
+		}
+		newMap.SetMapIndex(key, val)
+	}
+	field.Set(newMap)
+}
+
+// setFromString parses s according to the kind of field and sets it.
+func setFromString(field reflect.Value, s string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		intVal, err := strconv.ParseInt(s, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(intVal)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		uintVal, err := strconv.ParseUint(s, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(uintVal)
+	case reflect.Bool:
+		boolVal, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		field.SetBool(boolVal)
+	case reflect.Float32, reflect.Float64:
+		floatVal, err := strconv.ParseFloat(s, field.Type().Bits())
+		if err != nil {
+			return err
 		}
+		field.SetFloat(floatVal)
+	default:
+		return fmt.Errorf("%w: %v", ErrUnsupportedType, field.Kind())
+	}
+	return nil
+}
+
+// convertMapKey converts an input map key to keyType. String keys, such as
+// those decoded from JSON, are parsed for scalar key types so that map[int]T
+// can be filled from {"1": ...}, and with UnmarshalText for key types that
+// implement encoding.TextUnmarshaler, such as netip.Addr. Number and bool
+// keys are formatted for string key types. Other struct, array and pointer
+// key types can only be filled from convertible keys.
+func convertMapKey(key reflect.Value, keyType reflect.Type) (reflect.Value, error) {
+	if key.Kind() == reflect.Interface {
+		key = key.Elem()
+	}
+	if !key.IsValid() {
+		return reflect.Value{}, fmt.Errorf("cannot convert nil key to %v", keyType)
+	}
+	if key.Kind() == reflect.String && reflect.PointerTo(keyType).Implements(textUnmarshalerType) {
+		newKey := reflect.New(keyType)
+		if err := newKey.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(key.String())); err != nil {
+			return reflect.Value{}, fmt.Errorf("cannot convert key %q to %v: %v", key.String(), keyType, err)
+		}
+		return newKey.Elem(), nil
+	}
+	if keyType.Kind() == reflect.String && (isNumberKind(key.Kind()) || key.Kind() == reflect.Bool) {
+		// Format scalar keys, such as YAML's integer keys, instead of converting numbers as runes
+		return reflect.ValueOf(fmt.Sprint(key.Interface())).Convert(keyType), nil
+	}
+	if key.Type().ConvertibleTo(keyType) {
+		return key.Convert(keyType), nil
+	}
+	switch keyType.Kind() {
+	case reflect.Struct, reflect.Array, reflect.Ptr, reflect.Chan:
+		return reflect.Value{}, fmt.Errorf("%w: map key type %v can't be parsed from key %v", ErrUnsupportedType, keyType, key.Interface())
 	}
+	if key.Kind() == reflect.String && keyType.Kind() != reflect.String {
+		newKey := reflect.New(keyType).Elem()
+		if err := setFromString(newKey, key.String()); err != nil {
+			return reflect.Value{}, fmt.Errorf("cannot convert key %q to %v: %v", key.String(), keyType, err)
+		}
+		return newKey, nil
+	}
+	return reflect.Value{}, fmt.Errorf("cannot convert key of type %v to %v", key.Type(), keyType)
 }
 
-func convertType(value any, targetType reflect.Type) (any, error) {
-	val := reflect.ValueOf(value)
-	if val.Type().ConvertibleTo(targetType) {
-		return val.Convert(targetType).Interface(), nil
+// convertValue converts a slice element or map value to targetType. nil
+// becomes the zero value, json.Numbers are parsed for numeric types and
+// anything else must be convertible with reflect.Value.Convert.
+func (f *filler) convertValue(value reflect.Value, targetType reflect.Type) (reflect.Value, error) {
+	if value.Kind() == reflect.Interface {
+		value = value.Elem()
+	}
+	switch {
+	case !value.IsValid():
+		return reflect.Zero(targetType), nil
+	case value.Type() == targetType:
+		return value, nil
+	case isNumberKind(targetType.Kind()) && (value.Type() == jsonNumberType || isNumberKind(value.Kind())):
+		// Parse numbers like numeric fields so they can't wrap or truncate silently
+		converted := reflect.New(targetType).Elem()
+		if err := f.setNumber(converted, numberInput(value)); err != nil {
+			return reflect.Value{}, err
+		}
+		return converted, nil
+	case isNumberKind(value.Kind()) && targetType.Kind() == reflect.String:
+		// Go converts integers to strings as runes, so 65 would become "A"
+	case value.Type().ConvertibleTo(targetType):
+		return value.Convert(targetType), nil
 	}
-	return nil, fmt.Errorf("cannot convert %v to %v", val.Type(), targetType)
+	return reflect.Value{}, fmt.Errorf("cannot convert %v to %v", value.Type(), targetType)
 }
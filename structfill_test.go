@@ -2,12 +2,22 @@ package structfill
 
 import (
 	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"io"
 	"log"
+	"math"
+	"net"
+	"net/netip"
 	"os"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 // Primitives
@@ -194,6 +204,94 @@ func TestFill_EmbeddedStruct(t *testing.T) {
 	assert.Equal(t, B{A: A{Prop1: "value1"}, Prop2: 2}, b)
 }
 
+type C struct {
+	*A
+	Prop2 int
+}
+
+func TestFill_EmbeddedPointerStruct(t *testing.T) {
+	var c C
+	inputMap := map[string]any{
+		"prop1": "value1",
+		"prop2": 2,
+	}
+
+	err := Fill(&c, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, C{A: &A{Prop1: "value1"}, Prop2: 2}, c)
+}
+
+func TestFill_EmbeddedPointerLeftNilWithoutKeys(t *testing.T) {
+	var c C
+
+	err := Fill(&c, map[string]any{"prop2": 2})
+	assert.NoError(t, err)
+	assert.Equal(t, C{Prop2: 2}, c)
+}
+
+type Chain struct {
+	*Chain
+	X int
+}
+
+func TestFill_SelfEmbeddingPointer(t *testing.T) {
+	var chain Chain
+
+	assert.NotPanics(t, func() {
+		err := Fill(&chain, map[string]any{"x": 1})
+		assert.NoError(t, err)
+	})
+	// The outer X shadows every promoted X, so nothing is allocated
+	assert.Equal(t, Chain{X: 1}, chain)
+}
+
+type Extended struct {
+	*Base
+	Extra string `default:"extra"`
+}
+
+func TestFill_EmbeddedPointerDefaultsMatchApplyDefaults(t *testing.T) {
+	var filled, defaulted Extended
+
+	assert.NoError(t, Fill(&filled, nil))
+	assert.NoError(t, ApplyDefaults(&defaulted))
+	assert.Equal(t, Extended{Extra: "extra"}, filled)
+	assert.Equal(t, filled, defaulted)
+
+	// Embedded pointers that are already set get their defaults either way
+	filled, defaulted = Extended{Base: &Base{}}, Extended{Base: &Base{}}
+	assert.NoError(t, Fill(&filled, nil))
+	assert.NoError(t, ApplyDefaults(&defaulted))
+	assert.Equal(t, Extended{Base: &Base{Name: "base"}, Extra: "extra"}, filled)
+	assert.Equal(t, filled, defaulted)
+}
+
+type Base struct {
+	Name  string `default:"base"`
+	Label string
+	ID    int
+}
+
+type Derived struct {
+	Base
+	Name  string
+	Title string `fill:"label"`
+}
+
+func TestFill_EmbeddedFieldsShadowed(t *testing.T) {
+	var derived Derived
+	inputMap := map[string]any{
+		"name":  "outer",
+		"label": "title",
+		"id":    1,
+	}
+
+	err := Fill(&derived, inputMap)
+	assert.NoError(t, err)
+	// Shadowed fields of the embedded struct only get their defaults
+	assert.Equal(t, Derived{Base: Base{Name: "base", ID: 1}, Name: "outer", Title: "title"}, derived)
+}
+
 // Interfaces
 type Animal interface {
 	Speak() string
@@ -245,6 +343,98 @@ func TestFill_Interface(t *testing.T) {
 			&Cat{Pet: Pet{Name: "Whiskers"}, Wild: true},
 		},
 	}, house)
+	assert.Equal(t, "Woof!", house.Pets[0].Speak())
+	assert.Equal(t, "Meow!", house.Pets[1].Speak())
+}
+
+func TestFill_InterfaceSliceTypeMismatch(t *testing.T) {
+	var house House
+	inputMap := map[string]any{
+		"pets": []map[string]any{{"type": "Plain", "name": "Rex"}},
+	}
+
+	// Pet has no Speak method, so it must not end up in an []Animal
+	err := Fill(&house, inputMap, map[string]func() any{"Plain": func() any { return &Pet{} }})
+	assert.Error(t, err)
+	assert.Equal(t, "Pets[0]: type *structfill.Pet does not implement structfill.Animal for field Pets", err.Error())
+}
+
+type Street struct {
+	Corner House
+	Houses map[string]House
+	Blocks []Block
+}
+
+type Block struct {
+	Houses []*House
+}
+
+func TestFill_InterfaceInNestedStructs(t *testing.T) {
+	var street Street
+	inputMap := map[string]any{
+		"corner": map[string]any{
+			"pets": []any{map[string]any{"type": "Dog", "name": "Rex"}},
+		},
+		"houses": map[string]any{
+			"no1": map[string]any{
+				"pets": []any{map[string]any{"type": "Cat", "name": "Whiskers"}},
+			},
+		},
+		"blocks": []any{
+			map[string]any{
+				"houses": []any{
+					map[string]any{
+						"pets": []any{map[string]any{"type": "Dog", "name": "Fido"}},
+					},
+				},
+			},
+		},
+	}
+	var typeRegistry = map[string]func() any{
+		"Dog": func() any { return &Dog{} },
+		"Cat": func() any { return &Cat{} },
+	}
+
+	err := Fill(&street, inputMap, typeRegistry)
+	assert.NoError(t, err)
+	assert.Equal(t, Street{
+		Corner: House{Pets: []Animal{&Dog{Pet{Name: "Rex"}}}},
+		Houses: map[string]House{"no1": {Pets: []Animal{&Cat{Pet: Pet{Name: "Whiskers"}}}}},
+		Blocks: []Block{{Houses: []*House{{Pets: []Animal{&Dog{Pet{Name: "Fido"}}}}}}},
+	}, street)
+	assert.Equal(t, "Woof!", street.Blocks[0].Houses[0].Pets[0].Speak())
+	assert.Equal(t, "Meow!", street.Houses["no1"].Pets[0].Speak())
+}
+
+type Enclosure struct {
+	Animal
+	Keeper string
+}
+
+func TestFill_EmbeddedInterface(t *testing.T) {
+	var enclosure Enclosure
+	inputMap := map[string]any{
+		"type":   "Cat",
+		"name":   "Whiskers",
+		"wild":   true,
+		"keeper": "Bob",
+	}
+	var typeRegistry = map[string]func() any{
+		"Cat": func() any { return &Cat{} },
+	}
+
+	err := Fill(&enclosure, inputMap, typeRegistry)
+	assert.NoError(t, err)
+	assert.Equal(t, Enclosure{Animal: &Cat{Pet: Pet{Name: "Whiskers"}, Wild: true}, Keeper: "Bob"}, enclosure)
+	assert.Equal(t, "Meow!", enclosure.Speak())
+}
+
+func TestFill_EmbeddedInterfaceWithoutRegistry(t *testing.T) {
+	var enclosure Enclosure
+
+	err := Fill(&enclosure, map[string]any{"type": "Cat", "keeper": "Bob"})
+	assert.Error(t, err)
+	assert.Equal(t, "Animal: no type registry provided for embedded interface field Animal", err.Error())
 }
 
 // Deep nested
@@ -366,3 +556,1677 @@ func TestFill_WarningForMissingTypeIdentifier(t *testing.T) {
 		t.Errorf("Expected warning message for missing type identifier not found in log output")
 	}
 }
+
+// Pointers
+type Profile struct {
+	Nickname *string
+	Age      *int
+	Address  *Address
+}
+
+func TestFill_PointerFields(t *testing.T) {
+	var profile Profile
+	inputMap := map[string]any{
+		"nickname": "Al",
+		"age":      29,
+		"address": map[string]any{
+			"city": "Springfield",
+		},
+	}
+
+	err := Fill(&profile, inputMap)
+	assert.NoError(t, err)
+	if assert.NotNil(t, profile.Nickname) {
+		assert.Equal(t, "Al", *profile.Nickname)
+	}
+	if assert.NotNil(t, profile.Age) {
+		assert.Equal(t, 29, *profile.Age)
+	}
+	// Defaults on the pointed-to struct are applied after allocation
+	assert.Equal(t, &Address{Street: "Main St", City: "Springfield", Height: 1.8}, profile.Address)
+}
+
+func TestFill_PointerFieldsAbsent(t *testing.T) {
+	var profile Profile
+	inputMap := map[string]any{
+		"age": nil,
+	}
+
+	err := Fill(&profile, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, Profile{}, profile)
+}
+
+type Tuning struct {
+	Retries *int     `default:"3"`
+	Verbose *bool    `default:"true"`
+	Ratio   *float64 `default:"0.5"`
+	Limit   *int
+}
+
+func TestFill_PointerPrimitivesPresent(t *testing.T) {
+	var tuning Tuning
+	inputMap := map[string]any{
+		"retries": 0,
+		"verbose": false,
+		"ratio":   0.25,
+		"limit":   10,
+	}
+
+	err := Fill(&tuning, inputMap)
+	assert.NoError(t, err)
+	if assert.NotNil(t, tuning.Retries) && assert.NotNil(t, tuning.Verbose) && assert.NotNil(t, tuning.Ratio) && assert.NotNil(t, tuning.Limit) {
+		assert.Equal(t, 0, *tuning.Retries)
+		assert.Equal(t, false, *tuning.Verbose)
+		assert.Equal(t, 0.25, *tuning.Ratio)
+		assert.Equal(t, 10, *tuning.Limit)
+	}
+}
+
+func TestFill_PointerPrimitivesDefaulted(t *testing.T) {
+	var tuning Tuning
+
+	err := Fill(&tuning, map[string]any{})
+	assert.NoError(t, err)
+	if assert.NotNil(t, tuning.Retries) && assert.NotNil(t, tuning.Verbose) && assert.NotNil(t, tuning.Ratio) {
+		assert.Equal(t, 3, *tuning.Retries)
+		assert.Equal(t, true, *tuning.Verbose)
+		assert.Equal(t, 0.5, *tuning.Ratio)
+	}
+	// Without a default an absent pointer field stays nil
+	assert.Nil(t, tuning.Limit)
+}
+
+func TestFill_PointerPrimitivesAbsentWithoutDefaults(t *testing.T) {
+	var tuning Tuning
+
+	err := FillWithOptions(&tuning, map[string]any{}, Options{DisableDefaults: true})
+	assert.NoError(t, err)
+	assert.Equal(t, Tuning{}, tuning)
+}
+
+// Time
+type Event struct {
+	Start time.Time
+	Day   time.Time `format:"2006-01-02"`
+}
+
+func TestFill_Time(t *testing.T) {
+	var event Event
+	inputMap := map[string]any{
+		"start": "2024-03-01T10:30:00Z",
+		"day":   "2024-03-02",
+	}
+
+	err := Fill(&event, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, Event{
+		Start: time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC),
+		Day:   time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+	}, event)
+}
+
+func TestFill_TimeInvalidLayout(t *testing.T) {
+	var event Event
+	inputMap := map[string]any{
+		"day": "03/02/2024",
+	}
+
+	err := Fill(&event, inputMap)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid time for field Day, expected layout "2006-01-02"`)
+}
+
+type Schedule struct {
+	At time.Time `format:"2006-01-02T15:04:05Z07:00|2006-01-02 15:04:05|2006-01-02"`
+}
+
+func TestFill_TimeMultipleLayouts(t *testing.T) {
+	var schedule Schedule
+
+	for input, want := range map[string]time.Time{
+		"2024-03-01T10:30:00Z": time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC),
+		"2024-03-01 10:30:00":  time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC),
+		"2024-03-01":           time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		err := Fill(&schedule, map[string]any{"at": input})
+		assert.NoError(t, err)
+		assert.Equal(t, want, schedule.At, input)
+	}
+}
+
+func TestFill_TimeMultipleLayoutsNoneMatch(t *testing.T) {
+	var schedule Schedule
+
+	err := Fill(&schedule, map[string]any{"at": "March 1st"})
+	assert.Error(t, err)
+	assert.Equal(t, `At: invalid time for field At, expected one of layouts "2006-01-02T15:04:05Z07:00", "2006-01-02 15:04:05", "2006-01-02"`, err.Error())
+}
+
+type Subscription struct {
+	Since   time.Time  `default:"2020-01-01T00:00:00Z"`
+	Renews  *time.Time `default:"2021-06-30" format:"2006-01-02"`
+	Expires time.Time  `default:"soon"`
+}
+
+func TestFill_TimeDefaults(t *testing.T) {
+	var subscription Subscription
+
+	err := Fill(&subscription, map[string]any{})
+	assert.NoError(t, err)
+	renews := time.Date(2021, 6, 30, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, Subscription{
+		Since:  time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		Renews: &renews,
+	}, subscription)
+}
+
+// Durations
+type Timeouts struct {
+	Read  time.Duration
+	Write time.Duration
+}
+
+func TestFill_Duration(t *testing.T) {
+	var timeouts Timeouts
+	inputMap := map[string]any{
+		"read":  "30s",
+		"write": 1500,
+	}
+
+	err := Fill(&timeouts, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, Timeouts{Read: 30 * time.Second, Write: 1500 * time.Nanosecond}, timeouts)
+}
+
+func TestFill_DurationInvalid(t *testing.T) {
+	var timeouts Timeouts
+	inputMap := map[string]any{
+		"read": "soon",
+	}
+
+	err := Fill(&timeouts, inputMap)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid duration "soon" for field Read`)
+}
+
+func TestFill_DurationJSONNumber(t *testing.T) {
+	var timeouts Timeouts
+	inputMap := map[string]any{
+		"read":  json.Number("1e9"),
+		"write": json.Number("1500"),
+	}
+
+	err := Fill(&timeouts, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, Timeouts{Read: time.Second, Write: 1500 * time.Nanosecond}, timeouts)
+}
+
+// Unsigned integers
+type Counters struct {
+	Hits    uint  `default:"1"`
+	Retries uint8 `validate:"min=1,max=5"`
+	Total   uint64
+}
+
+func TestFill_UnsignedIntegers(t *testing.T) {
+	var counters Counters
+	inputMap := map[string]any{
+		"retries": 3,
+		"total":   "18446744073709551615",
+	}
+
+	err := Fill(&counters, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, Counters{Hits: 1, Retries: 3, Total: 18446744073709551615}, counters)
+}
+
+func TestFill_UnsignedIntegerErrors(t *testing.T) {
+	var counters Counters
+
+	err := Fill(&counters, map[string]any{"hits": -1})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid value -1 for field Hits, expected a non-negative integer")
+
+	err = Fill(&counters, map[string]any{"retries": 6})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "value 6 is greater than max 5")
+}
+
+// Error paths
+type Department struct {
+	Lead    Employee
+	Members []Employee
+}
+
+func TestFill_ErrorPath(t *testing.T) {
+	var department Department
+	inputMap := map[string]any{
+		"lead": map[string]any{"name": "Alice", "age": 40},
+		"members": []map[string]any{
+			{"name": "Bob", "age": 30},
+			{"name": "Charlie", "age": 17},
+		},
+	}
+
+	err := Fill(&department, inputMap)
+	assert.Error(t, err)
+	assert.Equal(t, "Members[1].Age: value 17 is less than min 18", err.Error())
+
+	var fieldErr *FieldError
+	if assert.True(t, errors.As(err, &fieldErr)) {
+		assert.Equal(t, "Members[1].Age", fieldErr.Path())
+		assert.Equal(t, "value 17 is less than min 18", fieldErr.Err().Error())
+	}
+}
+
+func TestFill_ErrorPathNestedStruct(t *testing.T) {
+	var department Department
+	inputMap := map[string]any{
+		"lead": map[string]any{"age": "forty"},
+	}
+
+	err := Fill(&department, inputMap)
+	var fieldErr *FieldError
+	if assert.True(t, errors.As(err, &fieldErr)) {
+		assert.Equal(t, "Lead.Age", fieldErr.Path())
+	}
+}
+
+// Output
+func TestFill_InterfaceSliceWritesNothingToStdout(t *testing.T) {
+	r, w, err := os.Pipe()
+	assert.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() {
+		os.Stdout = stdout
+	}()
+
+	var house House
+	inputMap := map[string]any{
+		"pets": []map[string]any{
+			{"type": "Dog", "name": "Rex"},
+		},
+	}
+	var typeRegistry = map[string]func() any{
+		"Dog": func() any { return &Dog{} },
+	}
+
+	err = Fill(&house, inputMap, typeRegistry)
+	assert.NoError(t, err)
+
+	w.Close()
+	os.Stdout = stdout
+	output, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Empty(t, string(output))
+}
+
+// Key names
+type User struct {
+	UserID    int    `fill:"user_id"`
+	FirstName string `fill:"first_name"`
+	Email     string
+}
+
+func TestFill_FillTagKeyName(t *testing.T) {
+	var user User
+	inputMap := map[string]any{
+		"user_id":    7,
+		"first_name": "Alice",
+		"email":      "alice@example.com",
+		"userid":     8, // Ignored, the tag overrides the lowercased name
+	}
+
+	err := Fill(&user, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, User{UserID: 7, FirstName: "Alice", Email: "alice@example.com"}, user)
+}
+
+// Slice and map defaults
+type Preferences struct {
+	Tags    []string          `default:"a,b,c"`
+	Ports   []int             `default:"80,443"`
+	Aliases []string          `default:"[]"`
+	Limits  map[string]int    `default:"cpu:2,mem:512"`
+	Labels  map[string]string `default:"{}"`
+	Extra   []string
+	Phrases []string          `default:"hello, world;goodbye" delim:";"`
+	Weights []float64         `default:"0.5|1.5" delim:"|"`
+	Origins map[string]string `default:"a:x,y;b:z" delim:";"`
+}
+
+func TestFill_SliceAndMapDefaults(t *testing.T) {
+	var prefs Preferences
+
+	err := Fill(&prefs, map[string]any{})
+	assert.NoError(t, err)
+	assert.Equal(t, Preferences{
+		Tags:    []string{"a", "b", "c"},
+		Ports:   []int{80, 443},
+		Aliases: []string{},
+		Limits:  map[string]int{"cpu": 2, "mem": 512},
+		Labels:  map[string]string{},
+		Phrases: []string{"hello, world", "goodbye"},
+		Weights: []float64{0.5, 1.5},
+		Origins: map[string]string{"a": "x,y", "b": "z"},
+	}, prefs)
+	assert.NotNil(t, prefs.Aliases)
+	assert.NotNil(t, prefs.Labels)
+	assert.Nil(t, prefs.Extra)
+}
+
+func TestFill_SliceDefaultsOverriddenByInput(t *testing.T) {
+	var prefs Preferences
+
+	err := Fill(&prefs, map[string]any{"tags": []string{"x"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"x"}, prefs.Tags)
+}
+
+// Tracking
+func TestFillTracked(t *testing.T) {
+	var person Employee
+	inputMap := map[string]any{
+		"age": 40,
+		"address": map[string]any{
+			"city": "Springfield",
+		},
+	}
+
+	filled, err := FillTracked(&person, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Age", "Address", "Address.City"}, filled)
+	assert.Equal(t, Employee{Name: "John Doe", Age: 40, Address: Address{Street: "Main St", City: "Springfield", Height: 1.8}}, person)
+}
+
+func TestFillTracked_NonStringForStringField(t *testing.T) {
+	var person Employee
+	inputMap := map[string]any{
+		"age": 40,
+		"address": map[string]any{
+			"city": 5,
+		},
+	}
+
+	filled, err := FillTracked(&person, inputMap)
+	assert.Error(t, err)
+	assert.Equal(t, "Address.City: invalid type for field City, expected string but got int", err.Error())
+	// Only fields whose value was actually set are tracked
+	assert.Equal(t, []string{"Age"}, filled)
+	assert.Equal(t, "", person.Address.City)
+}
+
+func TestFillTracked_Empty(t *testing.T) {
+	var person Employee
+
+	filled, err := FillTracked(&person, map[string]any{})
+	assert.NoError(t, err)
+	assert.Empty(t, filled)
+}
+
+// Field order
+type Audit struct {
+	First  int `validate:"min=1"`
+	Signup     // Name, then Age and Email
+	Nested Address
+	Last   int `validate:"min=1"`
+}
+
+func TestFill_ErrorsFollowFieldOrder(t *testing.T) {
+	valid := map[string]any{"first": 1, "name": "Ann", "age": 20, "nested": map[string]any{}, "last": 1}
+	// Each step fixes the previously reported field, so the next one in order surfaces
+	steps := []struct {
+		key   string
+		value any
+		want  string
+	}{
+		{"first", 0, "First: value 0 is less than min 1"},
+		{"name", nil, "Name: field Name is required"},
+		{"age", 1, "Age: value 1 is less than min 18"},
+		{"nested", map[string]any{"height": 3.0}, "Nested.Height: value 3 is greater than max 2"},
+		{"last", 0, "Last: value 0 is less than min 1"},
+	}
+	broken := map[string]any{}
+	for _, step := range steps {
+		broken[step.key] = step.value
+	}
+
+	for _, step := range steps {
+		// Repeat to make sure the result doesn't depend on map iteration order
+		for i := 0; i < 20; i++ {
+			var audit Audit
+			err := Fill(&audit, broken)
+			assert.Error(t, err)
+			assert.Equal(t, step.want, err.Error())
+		}
+		broken[step.key] = valid[step.key]
+	}
+
+	filled, err := FillTracked(new(Audit), valid)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"First", "Name", "Age", "Nested", "Last"}, filled)
+}
+
+// Float precision
+type Measurements struct {
+	Precise float64
+	Single  float32
+}
+
+func TestFill_FloatInputsKeepPrecision(t *testing.T) {
+	var m Measurements
+	inputMap := map[string]any{
+		"precise": 0.1 + 0.2,
+		"single":  float32(16777217),
+	}
+
+	err := Fill(&m, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.1+0.2, m.Precise)
+	assert.Equal(t, float32(16777217), m.Single)
+
+	for _, value := range []float64{math.MaxFloat64, math.SmallestNonzeroFloat64, -1.7976931348623157e308, 5e-324, 1.0000000000000002} {
+		err = Fill(&m, map[string]any{"precise": value})
+		assert.NoError(t, err)
+		assert.Equal(t, value, m.Precise)
+	}
+
+	err = Fill(&m, map[string]any{"single": math.MaxFloat32})
+	assert.NoError(t, err)
+	assert.Equal(t, float32(math.MaxFloat32), m.Single)
+}
+
+func TestFill_Float32Overflow(t *testing.T) {
+	var m Measurements
+
+	err := Fill(&m, map[string]any{"single": 1e39})
+	assert.Error(t, err)
+	assert.Equal(t, "Single: value 1e+39 overflows float32 (max 3.4028234663852886e+38)", err.Error())
+
+	err = Fill(&m, map[string]any{"single": "1e39"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "value out of range")
+}
+
+// Floats into integers
+type Sizes struct {
+	Small int8
+	Large int64
+	Count uint16
+}
+
+func TestFill_FloatInputsForIntegers(t *testing.T) {
+	var sizes Sizes
+	inputMap := map[string]any{
+		"small": 29.0,
+		"large": 1e6,
+		"count": 7.9,
+	}
+
+	err := Fill(&sizes, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, Sizes{Small: 29, Large: 1000000, Count: 7}, sizes)
+}
+
+func TestFill_FloatInputOverflow(t *testing.T) {
+	var sizes Sizes
+
+	err := Fill(&sizes, map[string]any{"small": 300.0})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "value 300 overflows int8 (max 127)")
+
+	err = Fill(&sizes, map[string]any{"count": 1e9})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "value 1e+09 overflows uint16 (max 65535)")
+}
+
+func TestFill_IntegerOverflowMessages(t *testing.T) {
+	var sizes Sizes
+
+	err := Fill(&sizes, map[string]any{"small": 300})
+	assert.Error(t, err)
+	assert.Equal(t, "Small: value 300 overflows int8 (max 127)", err.Error())
+
+	err = Fill(&sizes, map[string]any{"small": "-129"})
+	assert.Error(t, err)
+	assert.Equal(t, "Small: value -129 overflows int8 (min -128)", err.Error())
+
+	err = Fill(&sizes, map[string]any{"large": "9223372036854775808"})
+	assert.Error(t, err)
+	assert.Equal(t, "Large: value 9223372036854775808 overflows int64 (max 9223372036854775807)", err.Error())
+
+	err = Fill(&sizes, map[string]any{"count": 70000})
+	assert.Error(t, err)
+	assert.Equal(t, "Count: value 70000 overflows uint16 (max 65535)", err.Error())
+}
+
+// Map keys
+type Lookup struct {
+	Names  map[int]string
+	Scores map[uint8]float64
+}
+
+func TestFill_MapWithIntegerKeys(t *testing.T) {
+	var lookup Lookup
+	inputMap := map[string]any{
+		"names":  map[string]string{"1": "one", "2": "two"},
+		"scores": map[string]float64{"7": 1.5},
+	}
+
+	err := Fill(&lookup, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, Lookup{
+		Names:  map[int]string{1: "one", 2: "two"},
+		Scores: map[uint8]float64{7: 1.5},
+	}, lookup)
+}
+
+func TestFill_MapWithInvalidKey(t *testing.T) {
+	var lookup Lookup
+	inputMap := map[string]any{
+		"names": map[string]string{"one": "one"},
+	}
+
+	err := Fill(&lookup, inputMap)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid key for field Names: cannot convert key "one" to int`)
+}
+
+type AccountID string
+
+type GridCell struct {
+	X, Y int
+}
+
+type Registry struct {
+	Owners map[AccountID]string
+	Hosts  map[netip.Addr]string
+	Cells  map[GridCell]string
+}
+
+func TestFill_MapWithNamedAndTextKeys(t *testing.T) {
+	var registry Registry
+	inputMap := map[string]any{
+		"owners": map[string]any{"acc-1": "Ann"},
+		"hosts":  map[string]any{"10.0.0.1": "db"},
+		"cells":  map[GridCell]string{{X: 1, Y: 2}: "start"},
+	}
+
+	err := Fill(&registry, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, Registry{
+		Owners: map[AccountID]string{"acc-1": "Ann"},
+		Hosts:  map[netip.Addr]string{netip.MustParseAddr("10.0.0.1"): "db"},
+		Cells:  map[GridCell]string{{X: 1, Y: 2}: "start"},
+	}, registry)
+}
+
+func TestFill_MapWithUnsupportedKeys(t *testing.T) {
+	var registry Registry
+
+	err := Fill(&registry, map[string]any{"cells": map[string]any{"1,2": "start"}})
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+	assert.Equal(t, "Cells: invalid key for field Cells: unsupported type: map key type structfill.GridCell can't be parsed from key 1,2", err.Error())
+
+	err = Fill(&registry, map[string]any{"hosts": map[string]any{"not-an-ip": "db"}})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `Hosts: invalid key for field Hosts: cannot convert key "not-an-ip" to netip.Addr`)
+
+	err = Fill(&registry, map[string]any{"owners": map[any]any{nil: "Ann"}})
+	assert.Error(t, err)
+	assert.Equal(t, "Owners: invalid key for field Owners: cannot convert nil key to structfill.AccountID", err.Error())
+
+	err = Fill(&registry, map[string]any{"owners": map[any]any{3.5i: "Ann"}})
+	assert.Error(t, err)
+	assert.Equal(t, "Owners: invalid key for field Owners: cannot convert key of type complex128 to structfill.AccountID", err.Error())
+}
+
+// Struct values in maps
+type Directory struct {
+	Offices  map[string]Address
+	Branches map[string]*Address
+}
+
+func TestFill_MapOfStructs(t *testing.T) {
+	var directory Directory
+	inputMap := map[string]any{
+		"offices": map[string]any{
+			"hq": map[string]any{"city": "Springfield"},
+		},
+		"branches": map[string]any{
+			"east": map[string]any{"city": "Shelbyville", "street": "Elm St"},
+		},
+	}
+
+	err := Fill(&directory, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, Directory{
+		Offices:  map[string]Address{"hq": {Street: "Main St", City: "Springfield", Height: 1.8}},
+		Branches: map[string]*Address{"east": {Street: "Elm St", City: "Shelbyville", Height: 1.8}},
+	}, directory)
+}
+
+func TestFill_MapOfStructsErrorPath(t *testing.T) {
+	var directory Directory
+	inputMap := map[string]any{
+		"offices": map[string]any{
+			"hq": map[string]any{"height": 3.0},
+		},
+	}
+
+	err := Fill(&directory, inputMap)
+	assert.Error(t, err)
+	assert.Equal(t, "Offices[hq].Height: value 3 is greater than max 2", err.Error())
+}
+
+// Discriminators
+type Kennel struct {
+	Pets []Animal `fill:",discriminator=kind"`
+}
+
+func TestFill_DiscriminatorTag(t *testing.T) {
+	var kennel Kennel
+	inputMap := map[string]any{
+		"pets": []map[string]any{
+			{"kind": "Dog", "name": "Rex"},
+		},
+	}
+	var typeRegistry = map[string]func() any{
+		"Dog": func() any { return &Dog{} },
+	}
+
+	err := Fill(&kennel, inputMap, typeRegistry)
+	assert.NoError(t, err)
+	assert.Equal(t, Kennel{Pets: []Animal{&Dog{Pet{Name: "Rex"}}}}, kennel)
+}
+
+// Interface fields
+type Owner struct {
+	Name     string
+	Favorite Animal
+}
+
+func TestFill_InterfaceField(t *testing.T) {
+	var owner Owner
+	inputMap := map[string]any{
+		"name":     "Alice",
+		"favorite": map[string]any{"type": "Cat", "name": "Whiskers", "wild": true},
+	}
+	var typeRegistry = map[string]func() any{
+		"Cat": func() any { return &Cat{} },
+	}
+
+	err := Fill(&owner, inputMap, typeRegistry)
+	assert.NoError(t, err)
+	assert.Equal(t, Owner{Name: "Alice", Favorite: &Cat{Pet: Pet{Name: "Whiskers"}, Wild: true}}, owner)
+	assert.Equal(t, "Meow!", owner.Favorite.Speak())
+}
+
+func TestFill_InterfaceFieldUnknownType(t *testing.T) {
+	var owner Owner
+	inputMap := map[string]any{
+		"favorite": map[string]any{"type": "Parrot", "name": "Polly"},
+	}
+
+	err := Fill(&owner, inputMap)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "type identifier Parrot not found in type registry for field Favorite")
+}
+
+// Text unmarshalers
+type Version struct {
+	Major int
+	Minor int
+}
+
+func (v *Version) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscanf(string(text), "v%d.%d", &v.Major, &v.Minor)
+	return err
+}
+
+type Release struct {
+	Version Version
+	Latest  *Version
+}
+
+func TestFill_TextUnmarshaler(t *testing.T) {
+	var release Release
+	inputMap := map[string]any{
+		"version": "v1.2",
+		"latest":  "v1.3",
+	}
+
+	err := Fill(&release, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, Release{Version: Version{Major: 1, Minor: 2}, Latest: &Version{Major: 1, Minor: 3}}, release)
+}
+
+func TestFill_TextUnmarshalerError(t *testing.T) {
+	var release Release
+
+	err := Fill(&release, map[string]any{"version": "latest"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid value for field Version")
+}
+
+func TestFill_TextUnmarshalerFallsBackToMap(t *testing.T) {
+	var release Release
+
+	err := Fill(&release, map[string]any{"version": map[string]any{"major": 2}})
+	assert.NoError(t, err)
+	assert.Equal(t, Version{Major: 2}, release.Version)
+}
+
+type Host struct {
+	IP   net.IP
+	Addr netip.Addr
+}
+
+func TestFill_IPAddresses(t *testing.T) {
+	var host Host
+
+	err := Fill(&host, map[string]any{"ip": "192.168.1.1", "addr": "10.0.0.1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "192.168.1.1", host.IP.String())
+	assert.Equal(t, netip.MustParseAddr("10.0.0.1"), host.Addr)
+
+	err = Fill(&host, map[string]any{"ip": "2001:db8::1", "addr": "fe80::1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "2001:db8::1", host.IP.String())
+	assert.Equal(t, netip.MustParseAddr("fe80::1"), host.Addr)
+}
+
+func TestFill_IPAddressErrors(t *testing.T) {
+	var host Host
+
+	err := Fill(&host, map[string]any{"ip": "192.168.1"})
+	assert.Error(t, err)
+	assert.Equal(t, "IP: invalid value for field IP: invalid IP address: 192.168.1", err.Error())
+
+	err = Fill(&host, map[string]any{"addr": "not-an-ip"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid value for field Addr")
+}
+
+// Nil values
+func TestFill_NilValuesLikeMissingKeys(t *testing.T) {
+	var school School
+	inputMap := map[string]any{
+		"students":   nil,
+		"ages":       []any{1, nil},
+		"classrooms": []any{nil},
+	}
+
+	err := Fill(&school, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, School{Ages: []int{1, 0}, Classrooms: []Classroom{{}}}, school)
+}
+
+type Room struct {
+	Number int `default:"101"`
+}
+
+type Wing struct {
+	Name  string `default:"east"`
+	Room  Room
+	Spare *Room
+	Rooms []Room
+}
+
+type Campus struct {
+	Wing Wing
+}
+
+func TestFill_NilMapsApplyNestedDefaults(t *testing.T) {
+	want := Campus{Wing: Wing{Name: "east", Room: Room{Number: 101}}}
+
+	for name, inputMap := range map[string]map[string]any{
+		"nil input map":        nil,
+		"nil nested value":     {"wing": nil},
+		"nil nested map":       {"wing": map[string]any(nil)},
+		"nil deeper value":     {"wing": map[string]any{"room": nil, "spare": nil}},
+		"nil deeper map":       {"wing": map[string]any{"room": map[string]any(nil)}},
+		"empty deeper map":     {"wing": map[string]any{"room": map[string]any{}}},
+		"nil slice of structs": {"wing": map[string]any{"rooms": nil}},
+	} {
+		var campus Campus
+		err := Fill(&campus, inputMap)
+		assert.NoError(t, err, name)
+		assert.Equal(t, want, campus, name)
+	}
+}
+
+func TestFill_NilMapsInContainers(t *testing.T) {
+	var wing Wing
+
+	err := Fill(&wing, map[string]any{
+		"spare": map[string]any(nil),
+		"rooms": []any{map[string]any(nil), nil},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, Wing{Name: "east", Room: Room{Number: 101}, Spare: &Room{Number: 101}, Rooms: []Room{{Number: 101}, {}}}, wing)
+}
+
+func TestFill_NilValueFailsRequired(t *testing.T) {
+	var signup Signup
+
+	err := Fill(&signup, map[string]any{"name": nil, "age": 20})
+	assert.Error(t, err)
+	assert.Equal(t, "Name: field Name is required", err.Error())
+}
+
+// Fillables
+type Coordinates struct {
+	Lat, Lng float64
+}
+
+func (c *Coordinates) Fill(inputMap map[string]any) error {
+	latlng, ok := inputMap["latlng"].(string)
+	if !ok {
+		return fmt.Errorf("expected latlng string")
+	}
+	_, err := fmt.Sscanf(latlng, "%f,%f", &c.Lat, &c.Lng)
+	return err
+}
+
+type Landmark struct {
+	Name     string
+	Location Coordinates
+	Entrance *Coordinates
+}
+
+func TestFill_Fillable(t *testing.T) {
+	var landmark Landmark
+	inputMap := map[string]any{
+		"name":     "Tower",
+		"location": map[string]any{"latlng": "48.85,2.29"},
+		"entrance": map[string]any{"latlng": "48.86,2.3"},
+	}
+
+	err := Fill(&landmark, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, Landmark{
+		Name:     "Tower",
+		Location: Coordinates{Lat: 48.85, Lng: 2.29},
+		Entrance: &Coordinates{Lat: 48.86, Lng: 2.3},
+	}, landmark)
+}
+
+func TestFill_FillableError(t *testing.T) {
+	var landmark Landmark
+
+	err := Fill(&landmark, map[string]any{"location": map[string]any{"lat": 48.85}})
+	assert.Error(t, err)
+	assert.Equal(t, "Location: expected latlng string", err.Error())
+}
+
+// Environment defaults
+type ServerConfig struct {
+	Host string `env:"STRUCTFILL_TEST_HOST" default:"localhost"`
+	Port int    `env:"STRUCTFILL_TEST_PORT" default:"8080"`
+	Mode string `env:"STRUCTFILL_TEST_MODE"`
+}
+
+func TestFill_EnvDefaults(t *testing.T) {
+	t.Setenv("STRUCTFILL_TEST_PORT", "9090")
+	t.Setenv("STRUCTFILL_TEST_MODE", "debug")
+
+	var config ServerConfig
+	err := Fill(&config, map[string]any{"mode": "release"})
+	assert.NoError(t, err)
+	// Input map beats env, env beats the literal default
+	assert.Equal(t, ServerConfig{Host: "localhost", Port: 9090, Mode: "release"}, config)
+}
+
+// Defaults only
+type Defaulted struct {
+	A
+	Employee
+	Level int `default:"3"`
+}
+
+func TestApplyDefaults(t *testing.T) {
+	var defaulted Defaulted
+
+	err := ApplyDefaults(&defaulted)
+	assert.NoError(t, err)
+	assert.Equal(t, Defaulted{
+		Employee: Employee{Name: "John Doe", Age: 30, Address: Address{Street: "Main St", Height: 1.8}},
+		Level:    3,
+	}, defaulted)
+}
+
+func TestApplyDefaults_NonPointerInput(t *testing.T) {
+	err := ApplyDefaults(Defaulted{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "provided type must be a pointer to a struct")
+}
+
+// Remaining keys
+type Plugin struct {
+	Name  string
+	Extra map[string]any `fill:",remaining"`
+}
+
+func TestFill_RemainingKeys(t *testing.T) {
+	var plugin Plugin
+	inputMap := map[string]any{
+		"name":    "cache",
+		"size":    64,
+		"enabled": true,
+	}
+
+	err := Fill(&plugin, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, Plugin{Name: "cache", Extra: map[string]any{"size": 64, "enabled": true}}, plugin)
+}
+
+func TestFill_RemainingKeysNoneLeft(t *testing.T) {
+	var plugin Plugin
+
+	err := Fill(&plugin, map[string]any{"name": "cache"})
+	assert.NoError(t, err)
+	assert.Equal(t, Plugin{Name: "cache"}, plugin)
+}
+
+// Struct defaults
+type Office struct {
+	Name     string
+	Location Address `default:"{\"city\":\"Springfield\",\"height\":1.9}"`
+}
+
+func TestFill_StructDefaultJSON(t *testing.T) {
+	var office Office
+
+	err := Fill(&office, map[string]any{"name": "HQ"})
+	assert.NoError(t, err)
+	// Fields missing from the JSON default still get their own defaults
+	assert.Equal(t, Office{Name: "HQ", Location: Address{Street: "Main St", City: "Springfield", Height: 1.9}}, office)
+}
+
+func TestFill_StructDefaultJSONOverriddenByInput(t *testing.T) {
+	var office Office
+
+	err := Fill(&office, map[string]any{"location": map[string]any{"city": "Shelbyville"}})
+	assert.NoError(t, err)
+	assert.Equal(t, Address{Street: "Main St", City: "Shelbyville", Height: 1.8}, office.Location)
+}
+
+func TestFillTracked_StructDefaultJSONNotTracked(t *testing.T) {
+	var office Office
+
+	filled, err := FillTracked(&office, map[string]any{"name": "HQ"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Name"}, filled)
+}
+
+// Sentinel errors
+type Pipe struct {
+	Stream chan int
+}
+
+func TestFill_SentinelErrors(t *testing.T) {
+	err := Fill(Employee{}, map[string]any{})
+	assert.True(t, errors.Is(err, ErrNotStructPointer))
+
+	var pipe Pipe
+	err = Fill(&pipe, map[string]any{"stream": make(chan int)})
+	assert.True(t, errors.Is(err, ErrUnsupportedType))
+	assert.Equal(t, "Stream: unsupported type: chan", err.Error())
+
+	var person Employee
+	err = Fill(&person, map[string]any{"age": 17})
+	assert.True(t, errors.Is(err, ErrValidation))
+	assert.False(t, errors.Is(err, ErrUnsupportedType))
+}
+
+// Arrays
+type Point struct {
+	Coords [3]int
+	Weight [2]float64
+}
+
+func TestFill_Array(t *testing.T) {
+	var point Point
+	inputMap := map[string]any{
+		"coords": []int{1, 2, 3},
+		"weight": []any{0.5},
+	}
+
+	err := Fill(&point, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, Point{Coords: [3]int{1, 2, 3}, Weight: [2]float64{0.5, 0}}, point)
+}
+
+func TestFill_ArrayTooManyElements(t *testing.T) {
+	var point Point
+
+	err := Fill(&point, map[string]any{"coords": []int{1, 2, 3, 4}})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "too many elements for field Coords: got 4, array holds 3")
+}
+
+// Skipped fields
+type Credentials struct {
+	Username string
+	Hash     string `fill:"-" default:"unset"`
+}
+
+func TestFill_SkipField(t *testing.T) {
+	var creds Credentials
+	inputMap := map[string]any{
+		"username": "alice",
+		"hash":     "injected",
+	}
+
+	err := Fill(&creds, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{Username: "alice"}, creds)
+}
+
+func TestApplyDefaults_SkipField(t *testing.T) {
+	var creds Credentials
+
+	err := ApplyDefaults(&creds)
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{}, creds)
+}
+
+// Field metadata cache
+func TestFill_ConcurrentSameType(t *testing.T) {
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	people := make([]Employee, 8)
+	for i := range people {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = Fill(&people[i], map[string]any{
+				"name":    fmt.Sprintf("worker%d", i),
+				"age":     20 + i,
+				"address": map[string]any{"city": "Springfield"},
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	for i, person := range people {
+		assert.NoError(t, errs[i])
+		assert.Equal(t, fmt.Sprintf("worker%d", i), person.Name)
+		assert.Equal(t, 20+i, person.Age)
+		assert.Equal(t, "Springfield", person.Address.City)
+	}
+}
+
+// Top-level slices and maps
+func TestFillSlice(t *testing.T) {
+	var people []Employee
+	input := []any{
+		map[string]any{"name": "Alice", "age": 29},
+		map[string]any{"name": "Bob", "address": map[string]any{"city": "Springfield"}},
+	}
+
+	err := FillSlice(&people, input)
+	assert.NoError(t, err)
+	assert.Equal(t, []Employee{
+		{Name: "Alice", Age: 29, Address: Address{Street: "Main St", Height: 1.8}},
+		{Name: "Bob", Age: 30, Address: Address{Street: "Main St", City: "Springfield", Height: 1.8}},
+	}, people)
+}
+
+func TestFillSlice_ErrorPath(t *testing.T) {
+	var people []Employee
+
+	err := FillSlice(&people, []any{map[string]any{"age": 29}, map[string]any{"age": 10}})
+	assert.Error(t, err)
+	assert.Equal(t, "[1].Age: value 10 is less than min 18", err.Error())
+
+	err = FillSlice(people, []any{})
+	assert.Error(t, err)
+	assert.Equal(t, "provided type must be a pointer to a slice", err.Error())
+}
+
+func TestFillMap(t *testing.T) {
+	var people map[string]Employee
+	inputMap := map[string]any{
+		"alice": map[string]any{"name": "Alice", "age": 29},
+	}
+
+	err := FillMap(&people, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]Employee{
+		"alice": {Name: "Alice", Age: 29, Address: Address{Street: "Main St", Height: 1.8}},
+	}, people)
+
+	var counts map[string]int
+	err = FillMap(&counts, map[string]any{"a": 1, "b": 2.0})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, counts)
+}
+
+// Maps with other value types
+func TestFill_NestedStructFromStringMap(t *testing.T) {
+	var person Employee
+	inputMap := map[string]any{
+		"address": map[string]string{"street": "Elm St", "city": "Springfield", "height": "1.7"},
+	}
+
+	err := Fill(&person, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, Address{Street: "Elm St", City: "Springfield", Height: 1.7}, person.Address)
+}
+
+func TestFill_SliceOfStructsFromStringMaps(t *testing.T) {
+	var school School
+	inputMap := map[string]any{
+		"classrooms": []map[string]string{{"building": "A", "number": "101"}},
+	}
+
+	err := Fill(&school, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, []Classroom{{Building: "A", Number: 101}}, school.Classrooms)
+}
+
+func TestFill_NestedStructFromNonStringKeys(t *testing.T) {
+	var person Employee
+
+	err := Fill(&person, map[string]any{"address": map[int]any{1: "Elm St"}})
+	assert.Error(t, err)
+	assert.Equal(t, "Address: invalid type for field Address, expected map[string]any for nested struct", err.Error())
+}
+
+func TestFill_YAMLStyleInterfaceKeyMaps(t *testing.T) {
+	var street Street
+	// gopkg.in/yaml.v2 decodes every nested mapping as map[interface{}]interface{}
+	inputMap := map[string]any{
+		"corner": map[any]any{
+			"pets": []any{map[any]any{"type": "Dog", "name": "Rex"}},
+		},
+		"houses": map[any]any{
+			1: map[any]any{"pets": []any{map[any]any{"type": "Cat", "name": "Tom", "wild": true}}},
+		},
+	}
+	typeRegistry := map[string]func() any{
+		"Dog": func() any { return &Dog{} },
+		"Cat": func() any { return &Cat{} },
+	}
+
+	err := Fill(&street, inputMap, typeRegistry)
+	assert.NoError(t, err)
+	assert.Equal(t, Street{
+		Corner: House{Pets: []Animal{&Dog{Pet{Name: "Rex"}}}},
+		Houses: map[string]House{"1": {Pets: []Animal{&Cat{Pet: Pet{Name: "Tom"}, Wild: true}}}},
+	}, street)
+}
+
+func TestFill_InterfaceKeyMapWithNonScalarKey(t *testing.T) {
+	var person Employee
+
+	err := Fill(&person, map[string]any{"address": map[any]any{"city": "Springfield", [2]int{1, 2}: "Elm St"}})
+	assert.Error(t, err)
+	assert.Equal(t, "Address: invalid type for field Address, expected map[string]any for nested struct", err.Error())
+}
+
+// Method defaults
+type Session struct {
+	ID      string
+	Timeout int `default:"30"`
+	Retries int `default:"1"`
+	User    string
+}
+
+func (s *Session) Defaults() map[string]any {
+	return map[string]any{
+		"id":      "generated",
+		"timeout": 60,
+	}
+}
+
+func TestFill_DefaulterMergedUnderInput(t *testing.T) {
+	var session Session
+
+	err := Fill(&session, map[string]any{"user": "alice"})
+	assert.NoError(t, err)
+	assert.Equal(t, Session{ID: "generated", Timeout: 60, Retries: 1, User: "alice"}, session)
+
+	session = Session{}
+	err = Fill(&session, map[string]any{"id": "abc", "timeout": 5})
+	assert.NoError(t, err)
+	assert.Equal(t, Session{ID: "abc", Timeout: 5, Retries: 1}, session)
+
+	session = Session{}
+	err = Fill(&session, map[string]any{"id": "abc"})
+	assert.NoError(t, err)
+	assert.Equal(t, Session{ID: "abc", Timeout: 60, Retries: 1}, session)
+}
+
+func TestFillWithOptions_DefaulterCaseInsensitive(t *testing.T) {
+	var session Session
+
+	err := FillWithOptions(&session, map[string]any{"ID": "input"}, Options{KeyMatching: MatchCaseInsensitive})
+	assert.NoError(t, err)
+	assert.Equal(t, Session{ID: "input", Timeout: 60, Retries: 1}, session)
+}
+
+type Workspace struct {
+	Name    string
+	Session Session
+}
+
+func TestFill_NestedDefaulterWithoutKey(t *testing.T) {
+	want := Workspace{Session: Session{ID: "generated", Timeout: 60, Retries: 1}}
+
+	var workspace Workspace
+	err := Fill(&workspace, map[string]any{})
+	assert.NoError(t, err)
+	assert.Equal(t, want, workspace)
+
+	workspace = Workspace{}
+	err = Fill(&workspace, map[string]any{"session": map[string]any{}})
+	assert.NoError(t, err)
+	assert.Equal(t, want, workspace)
+
+	workspace = Workspace{}
+	err = ApplyDefaults(&workspace)
+	assert.NoError(t, err)
+	assert.Equal(t, want, workspace)
+}
+
+func TestFillTracked_DefaulterNotTracked(t *testing.T) {
+	var session Session
+
+	filled, err := FillTracked(&session, map[string]any{"user": "alice"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"User"}, filled)
+}
+
+// Empty interfaces
+type Envelope struct {
+	Kind    string
+	Data    any
+	Items   []any
+	Headers map[string]any
+}
+
+func TestFill_EmptyInterfaceFields(t *testing.T) {
+	var envelope Envelope
+	inputMap := map[string]any{
+		"kind":    "event",
+		"data":    map[string]any{"type": "Dog", "id": 7},
+		"items":   []any{1, "two", nil, map[string]any{"three": 3}},
+		"headers": map[string]any{"trace": "abc"},
+	}
+
+	err := Fill(&envelope, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, Envelope{
+		Kind:    "event",
+		Data:    map[string]any{"type": "Dog", "id": 7},
+		Items:   []any{1, "two", nil, map[string]any{"three": 3}},
+		Headers: map[string]any{"trace": "abc"},
+	}, envelope)
+
+	err = Fill(&envelope, map[string]any{"data": 42})
+	assert.NoError(t, err)
+	assert.Equal(t, 42, envelope.Data)
+}
+
+// Generic wrapper
+func TestFillChecked(t *testing.T) {
+	person, err := FillChecked[Employee](map[string]any{"name": "Alice", "age": 29})
+	assert.NoError(t, err)
+	assert.Equal(t, Employee{Name: "Alice", Age: 29, Address: Address{Street: "Main St", Height: 1.8}}, person)
+
+	_, err = FillChecked[Employee](map[string]any{"age": 10})
+	assert.Error(t, err)
+	assert.Equal(t, "Age: value 10 is less than min 18", err.Error())
+
+	_, err = FillChecked[int](map[string]any{})
+	assert.ErrorIs(t, err, ErrNotStructPointer)
+}
+
+func TestFillChecked_TypeRegistry(t *testing.T) {
+	house, err := FillChecked[House](map[string]any{
+		"pets": []any{map[string]any{"type": "Dog", "name": "Rex"}},
+	}, map[string]func() any{"Dog": func() any { return &Dog{} }})
+	assert.NoError(t, err)
+	assert.Equal(t, House{Pets: []Animal{&Dog{Pet{Name: "Rex"}}}}, house)
+}
+
+// Byte slices
+type Blob struct {
+	Data     []byte
+	Checksum []byte `format:"hex"`
+	Raw      []byte `format:"raw"`
+}
+
+func TestFill_ByteSlices(t *testing.T) {
+	var blob Blob
+	inputMap := map[string]any{
+		"data":     "aGVsbG8=",
+		"checksum": "cafe01",
+		"raw":      "plain",
+	}
+
+	err := Fill(&blob, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, Blob{
+		Data:     []byte("hello"),
+		Checksum: []byte{0xca, 0xfe, 0x01},
+		Raw:      []byte("plain"),
+	}, blob)
+
+	err = Fill(&blob, map[string]any{"data": []byte("direct")})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("direct"), blob.Data)
+}
+
+func TestFill_ByteSlicesInvalid(t *testing.T) {
+	var blob Blob
+
+	err := Fill(&blob, map[string]any{"data": "not base64!"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Data: invalid bytes for field Data")
+
+	err = Fill(&blob, map[string]any{"checksum": "xyz"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Checksum: invalid bytes for field Checksum")
+}
+
+// Value conversion
+func TestConvertValue(t *testing.T) {
+	converted, err := ConvertValue(29.0, reflect.TypeOf(0))
+	assert.NoError(t, err)
+	assert.Equal(t, 29, converted)
+
+	converted, err = ConvertValue(Rectangle, reflect.TypeOf(0))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, converted)
+
+	converted, err = ConvertValue(json.Number("42"), reflect.TypeOf(uint8(0)))
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(42), converted)
+
+	converted, err = ConvertValue(nil, reflect.TypeOf(""))
+	assert.NoError(t, err)
+	assert.Equal(t, "", converted)
+}
+
+func TestConvertValue_Overflow(t *testing.T) {
+	_, err := ConvertValue(300, reflect.TypeOf(int8(0)))
+	assert.Error(t, err)
+	assert.Equal(t, "value 300 overflows int8 (max 127)", err.Error())
+
+	_, err = ConvertValue(-1, reflect.TypeOf(uint16(0)))
+	assert.Error(t, err)
+	assert.Equal(t, "invalid value -1, expected a non-negative integer", err.Error())
+}
+
+type Readings struct {
+	Small  []int8
+	Counts []uint16
+	Levels map[string]int8
+}
+
+func TestFill_NumberElementOverflow(t *testing.T) {
+	for _, tc := range []struct {
+		input map[string]any
+		want  string
+	}{
+		{map[string]any{"small": []any{300}}, "Small[0]: error converting slice element for field Small: value 300 overflows int8 (max 127)"},
+		{map[string]any{"counts": []any{-1}}, "Counts[0]: error converting slice element for field Counts: invalid value -1, expected a non-negative integer"},
+		{map[string]any{"levels": map[string]any{"high": 1e3}}, "Levels[high]: value 1000 overflows int8 (max 127)"},
+	} {
+		var readings Readings
+
+		err := Fill(&readings, tc.input)
+		assert.Error(t, err)
+		assert.Equal(t, tc.want, err.Error())
+	}
+}
+
+func TestConvertValue_NotConvertible(t *testing.T) {
+	_, err := ConvertValue("29", reflect.TypeOf(0))
+	assert.Error(t, err)
+	assert.Equal(t, "cannot convert string to int", err.Error())
+
+	_, err = ConvertValue(map[string]any{}, reflect.TypeOf(Address{}))
+	assert.Error(t, err)
+	assert.Equal(t, "cannot convert map[string]interface {} to structfill.Address", err.Error())
+}
+
+// Slices of maps
+type Tally struct {
+	Rounds []map[string]int
+}
+
+func TestFill_SliceOfMaps(t *testing.T) {
+	var tally Tally
+	inputMap := map[string]any{
+		"rounds": []any{
+			map[string]any{"alice": 3.0, "bob": 1.0},
+			map[string]any{"alice": 2.0},
+		},
+	}
+
+	err := Fill(&tally, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, Tally{Rounds: []map[string]int{{"alice": 3, "bob": 1}, {"alice": 2}}}, tally)
+}
+
+func TestFill_SliceOfMapsErrorPath(t *testing.T) {
+	var tally Tally
+	inputMap := map[string]any{
+		"rounds": []any{
+			map[string]any{"alice": 3.0},
+			map[string]any{"bob": "one"},
+		},
+	}
+
+	err := Fill(&tally, inputMap)
+	assert.Error(t, err)
+	assert.Equal(t, "Rounds[1][bob]: cannot convert string to int", err.Error())
+}
+
+// Defined types
+type (
+	Seconds  int64
+	Ratio    float32
+	Label    string
+	Enabled  bool
+	Priority uint8
+)
+
+type Job struct {
+	Timeout  Seconds
+	Ratio    Ratio
+	Label    Label
+	Enabled  Enabled
+	Priority Priority
+	Retries  []Seconds
+	Tags     map[Label]Priority
+	Aliases  [2]Label
+	Deadline *Seconds
+}
+
+func TestFill_DefinedTypes(t *testing.T) {
+	var job Job
+	inputMap := map[string]any{
+		"timeout":  "30",
+		"ratio":    0.5,
+		"label":    "nightly",
+		"enabled":  "true",
+		"priority": 7.0,
+		"retries":  []any{1.0, int32(2), json.Number("3")},
+		"tags":     map[string]any{"urgent": 9.0},
+		"aliases":  []any{"n", Label("night")},
+		"deadline": 60.0,
+	}
+
+	err := Fill(&job, inputMap)
+	assert.NoError(t, err)
+	deadline := Seconds(60)
+	assert.Equal(t, Job{
+		Timeout:  30,
+		Ratio:    0.5,
+		Label:    "nightly",
+		Enabled:  true,
+		Priority: 7,
+		Retries:  []Seconds{1, 2, 3},
+		Tags:     map[Label]Priority{"urgent": 9},
+		Aliases:  [2]Label{"n", "night"},
+		Deadline: &deadline,
+	}, job)
+}
+
+func TestFill_IncompatibleSliceElements(t *testing.T) {
+	for _, tc := range []struct {
+		key   string
+		input []any
+		want  string
+	}{
+		{"retries", []any{1.0, Label("x")}, "Retries[1]: error converting slice element for field Retries: cannot convert structfill.Label to structfill.Seconds"},
+		{"retries", []any{map[string]any{"n": 1}}, "Retries[0]: error converting slice element for field Retries: cannot convert map[string]interface {} to structfill.Seconds"},
+		{"aliases", []any{"a", true}, "Aliases[1]: error converting slice element for field Aliases: cannot convert bool to structfill.Label"},
+		{"aliases", []any{[]string{"a"}}, "Aliases[0]: error converting slice element for field Aliases: cannot convert []string to structfill.Label"},
+	} {
+		var job Job
+
+		assert.NotPanics(t, func() {
+			err := Fill(&job, map[string]any{tc.key: tc.input})
+			assert.Error(t, err)
+			assert.Equal(t, tc.want, err.Error())
+		})
+	}
+}
+
+func TestFill_NumberIntoStringElement(t *testing.T) {
+	var job Job
+
+	err := Fill(&job, map[string]any{"aliases": []any{65}})
+	assert.Error(t, err)
+	assert.Equal(t, "Aliases[0]: error converting slice element for field Aliases: cannot convert int to structfill.Label", err.Error())
+}
+
+// SQL null types
+type Customer struct {
+	Nickname sql.NullString
+	Visits   sql.NullInt64
+	Balance  sql.NullFloat64
+	Verified sql.NullBool
+	Since    sql.NullTime
+}
+
+func TestFill_SQLNullTypes(t *testing.T) {
+	var customer Customer
+	since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	inputMap := map[string]any{
+		"nickname": "Ann",
+		"visits":   12.0,
+		"balance":  7.5,
+		"verified": true,
+		"since":    since,
+	}
+
+	err := Fill(&customer, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, Customer{
+		Nickname: sql.NullString{String: "Ann", Valid: true},
+		Visits:   sql.NullInt64{Int64: 12, Valid: true},
+		Balance:  sql.NullFloat64{Float64: 7.5, Valid: true},
+		Verified: sql.NullBool{Bool: true, Valid: true},
+		Since:    sql.NullTime{Time: since, Valid: true},
+	}, customer)
+}
+
+func TestFill_SQLNullTypesNullOrAbsent(t *testing.T) {
+	var customer Customer
+
+	err := Fill(&customer, map[string]any{"nickname": nil, "visits": nil})
+	assert.NoError(t, err)
+	assert.Equal(t, Customer{}, customer)
+	assert.False(t, customer.Nickname.Valid)
+	assert.False(t, customer.Since.Valid)
+}
+
+func TestFill_SQLNullTypesInvalid(t *testing.T) {
+	var customer Customer
+
+	err := Fill(&customer, map[string]any{"visits": "many"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Visits: invalid value for field Visits")
+}
+
+// Masked fills
+func TestFillMasked(t *testing.T) {
+	person := Employee{Name: "Alice", Age: 40, Address: Address{Street: "Elm St", City: "Springfield", Height: 1.7}}
+	inputMap := map[string]any{
+		"name": "Bob",
+		"age":  50,
+		"address": map[string]any{
+			"street": "Oak St",
+			"city":   "Shelbyville",
+		},
+	}
+
+	err := FillMasked(&person, inputMap, []string{"Age", "Address.City"})
+	assert.NoError(t, err)
+	assert.Equal(t, Employee{Name: "Alice", Age: 50, Address: Address{Street: "Elm St", City: "Shelbyville", Height: 1.7}}, person)
+
+	err = FillMasked(&person, inputMap, []string{"Address"})
+	assert.NoError(t, err)
+	assert.Equal(t, Employee{Name: "Alice", Age: 50, Address: Address{Street: "Oak St", City: "Shelbyville", Height: 1.7}}, person)
+}
+
+func TestFillMasked_NoDefaultsOrRequired(t *testing.T) {
+	var person Employee
+
+	err := FillMasked(&person, map[string]any{"age": 20}, []string{"Name", "Age", "Address"})
+	assert.NoError(t, err)
+	assert.Equal(t, Employee{Age: 20}, person)
+
+	var signup Signup
+	err = FillMasked(&signup, map[string]any{"age": 20}, []string{"Name", "Age"})
+	assert.NoError(t, err, "masked fields without a key are not required")
+	assert.Equal(t, Signup{Age: 20}, signup)
+}
+
+func TestFillMasked_ValidatesMaskedFields(t *testing.T) {
+	person := Employee{Age: 40}
+
+	err := FillMasked(&person, map[string]any{"age": 10, "name": 7}, []string{"Age"})
+	assert.Error(t, err)
+	assert.Equal(t, "Age: value 10 is less than min 18", err.Error())
+}
+
+type Lodger struct {
+	Name    string
+	Address *Address
+}
+
+func TestFillMasked_ExistingPointerFilledInPlace(t *testing.T) {
+	lodger := Lodger{Name: "Alice", Address: &Address{Street: "Elm St", City: "Springfield"}}
+
+	err := FillMasked(&lodger, map[string]any{"address": map[string]any{"city": "Shelbyville"}}, []string{"Address.City"})
+	assert.NoError(t, err)
+	assert.Equal(t, Lodger{Name: "Alice", Address: &Address{Street: "Elm St", City: "Shelbyville"}}, lodger)
+}
+
+func TestFillMasked_SliceElementsFilledWhole(t *testing.T) {
+	school := School{Classrooms: []Classroom{{Building: "A", Number: 101}}}
+
+	err := FillMasked(&school, map[string]any{"classrooms": []any{map[string]any{"building": "B", "number": 202}}}, []string{"Classrooms.Building"})
+	assert.NoError(t, err)
+	// The slice is replaced, so its new elements are filled in full
+	assert.Equal(t, []Classroom{{Building: "B", Number: 202}}, school.Classrooms)
+}
+
+func TestFillMasked_EmbeddedPointerOutsideMask(t *testing.T) {
+	var c C
+
+	err := FillMasked(&c, map[string]any{"prop1": "value1", "prop2": 2}, []string{"Prop2"})
+	assert.NoError(t, err)
+	assert.Equal(t, C{Prop2: 2}, c)
+}
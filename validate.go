@@ -0,0 +1,399 @@
+package structfill
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// rule is a single name=value entry from a validate tag.
+type rule struct {
+	name  string
+	value string
+}
+
+// hasRule reports whether rules contain the named rule.
+func hasRule(rules []rule, name string) bool {
+	for _, r := range rules {
+		if r.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validateRequired checks the required rule against the raw input value.
+// A missing key, a nil value or a zero value all fail the check.
+func validateRequired(rules []rule, fieldName string, inputValue any, ok bool) error {
+	if !hasRule(rules, "required") {
+		return nil
+	}
+	if !ok || inputValue == nil || reflect.ValueOf(inputValue).IsZero() {
+		return validationErrorf(rule{name: "required"}, inputValue, "field %s is required", fieldName)
+	}
+	return nil
+}
+
+// fieldRules parses the validate tag of a field into the rules for the field
+// itself and, following a "dive" marker, the rules for each of its elements.
+// For example `validate:"min=1,dive,min=0,max=120"` on a []int requires at
+// least one element and bounds every element to 0..120.
+// Map fields have no rules of their own: all of their rules apply to each
+// value.
+func fieldRules(tag reflect.StructTag) ([]rule, []rule, error) {
+	rules, err := parseRules(tag)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, r := range rules {
+		if r.name == "dive" {
+			return rules[:i], rules[i+1:], nil
+		}
+	}
+	return rules, nil, nil
+}
+
+// parseRules splits a validate tag such as `validate:"min=1,max=5"` into its
+// rules. A regex rule may itself contain commas, so it consumes the rest of
+// the tag and must be the last rule, e.g. `validate:"min=3,regex=^[a-z]{3,8}$"`.
+func parseRules(tag reflect.StructTag) ([]rule, error) {
+	validateTag := tag.Get("validate")
+	if validateTag == "" {
+		return nil, nil // No validation rules
+	}
+
+	var rules []rule
+	parts := strings.Split(validateTag, ",")
+	for i, r := range parts {
+		if pattern, ok := strings.CutPrefix(r, "regex="); ok {
+			pattern = strings.Join(append([]string{pattern}, parts[i+1:]...), ",")
+			rules = append(rules, rule{name: "regex", value: pattern})
+			break
+		}
+
+		ruleParts := strings.SplitN(r, "=", 2)
+		if ruleParts[0] == "" {
+			return nil, errors.New("invalid validate tag format")
+		}
+		if len(ruleParts) == 1 {
+			// Flag rules such as "required" carry no value
+			rules = append(rules, rule{name: ruleParts[0]})
+			continue
+		}
+		rules = append(rules, rule{name: ruleParts[0], value: ruleParts[1]})
+	}
+	return rules, nil
+}
+
+func validateIntField(rules []rule, value int64, custom map[string]ValidationFunc) error {
+	for _, r := range rules {
+		switch r.name {
+		case "required":
+			continue // Checked against the raw input before conversion
+		case "min", "max":
+			ruleValue, err := strconv.ParseInt(r.value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid rule value: %v", err)
+			}
+			if r.name == "min" && value < ruleValue {
+				return validationErrorf(r, value, "value %d is less than min %d", value, ruleValue)
+			}
+			if r.name == "max" && value > ruleValue {
+				return validationErrorf(r, value, "value %d is greater than max %d", value, ruleValue)
+			}
+		case "gt", "gte", "lt", "lte":
+			ruleValue, err := strconv.ParseInt(r.value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid rule value: %v", err)
+			}
+			if !compareRule(r.name, value, ruleValue) {
+				return validationErrorf(r, value, "value %d must be %s %d", value, comparisonOperators[r.name], ruleValue)
+			}
+		default:
+			if err := customRule(custom, r, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateUintField(rules []rule, value uint64, custom map[string]ValidationFunc) error {
+	for _, r := range rules {
+		switch r.name {
+		case "required":
+			continue // Checked against the raw input before conversion
+		case "min", "max":
+			ruleValue, err := strconv.ParseUint(r.value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid rule value: %v", err)
+			}
+			if r.name == "min" && value < ruleValue {
+				return validationErrorf(r, value, "value %d is less than min %d", value, ruleValue)
+			}
+			if r.name == "max" && value > ruleValue {
+				return validationErrorf(r, value, "value %d is greater than max %d", value, ruleValue)
+			}
+		case "gt", "gte", "lt", "lte":
+			ruleValue, err := strconv.ParseUint(r.value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid rule value: %v", err)
+			}
+			if !compareRule(r.name, value, ruleValue) {
+				return validationErrorf(r, value, "value %d must be %s %d", value, comparisonOperators[r.name], ruleValue)
+			}
+		default:
+			if err := customRule(custom, r, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateStringField applies min/max/len rules to the length of a string value
+// and checks oneof and regex rules, e.g. `validate:"oneof=red green blue"`, as
+// well as the email, url and uuid format rules.
+func validateStringField(rules []rule, value string, custom map[string]ValidationFunc) error {
+	length := len(value)
+	for _, r := range rules {
+		switch r.name {
+		case "required":
+			continue // Checked against the raw input before conversion
+		case "min", "max":
+			ruleValue, err := strconv.Atoi(r.value)
+			if err != nil {
+				return fmt.Errorf("invalid rule value: %v", err)
+			}
+			if r.name == "min" && length < ruleValue {
+				return validationErrorf(r, value, "length %d is less than min %d", length, ruleValue)
+			}
+			if r.name == "max" && length > ruleValue {
+				return validationErrorf(r, value, "length %d is greater than max %d", length, ruleValue)
+			}
+		case "len":
+			ruleValue, err := strconv.Atoi(r.value)
+			if err != nil {
+				return fmt.Errorf("invalid rule value: %v", err)
+			}
+			if length != ruleValue {
+				return validationErrorf(r, value, "length %d does not equal required %d", length, ruleValue)
+			}
+		case "regex":
+			re, err := compileRegex(r.value)
+			if err != nil {
+				return fmt.Errorf("invalid rule value: %v", err)
+			}
+			if !re.MatchString(value) {
+				return validationErrorf(r, value, "value '%s' does not match pattern %s", value, r.value)
+			}
+		case "oneof":
+			options := strings.Fields(r.value)
+			if !slices.Contains(options, value) {
+				return validationErrorf(r, value, "value '%s' is not one of [%s]", value, strings.Join(options, " "))
+			}
+		case "email":
+			// Only a bare address is accepted, not a display name form such as "Ann <ann@example.com>"
+			if addr, err := mail.ParseAddress(value); err != nil || addr.Address != value {
+				return validationErrorf(r, value, "value '%s' is not a valid email address", value)
+			}
+		case "url":
+			if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+				return validationErrorf(r, value, "value '%s' is not a valid URL", value)
+			}
+		case "uuid":
+			if !isUUID(value) {
+				return validationErrorf(r, value, "value '%s' is not a valid UUID", value)
+			}
+		default:
+			if err := customRule(custom, r, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateFloatField(rules []rule, value float64, custom map[string]ValidationFunc) error {
+	for _, r := range rules {
+		switch r.name {
+		case "required":
+			continue // Checked against the raw input before conversion
+		case "min", "max":
+			ruleValue, err := strconv.ParseFloat(r.value, 64)
+			if err != nil {
+				return fmt.Errorf("invalid rule value: %v", err)
+			}
+			if r.name == "min" && value < ruleValue {
+				return validationErrorf(r, value, "value %v is less than min %v", value, ruleValue)
+			}
+			if r.name == "max" && value > ruleValue {
+				return validationErrorf(r, value, "value %v is greater than max %v", value, ruleValue)
+			}
+		case "gt", "gte", "lt", "lte":
+			ruleValue, err := strconv.ParseFloat(r.value, 64)
+			if err != nil {
+				return fmt.Errorf("invalid rule value: %v", err)
+			}
+			if !compareRule(r.name, value, ruleValue) {
+				return validationErrorf(r, value, "value %v must be %s %v", value, comparisonOperators[r.name], ruleValue)
+			}
+		default:
+			if err := customRule(custom, r, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateBoolField checks eq rules, e.g. `validate:"eq=true"` for a flag that
+// must be set.
+func validateBoolField(rules []rule, value bool, custom map[string]ValidationFunc) error {
+	for _, r := range rules {
+		switch r.name {
+		case "required":
+			continue // Checked against the raw input before conversion
+		case "eq":
+			ruleValue, err := strconv.ParseBool(r.value)
+			if err != nil {
+				return fmt.Errorf("invalid rule value: %v", err)
+			}
+			if value != ruleValue {
+				return validationErrorf(r, value, "value %t is not equal to %t", value, ruleValue)
+			}
+		default:
+			if err := customRule(custom, r, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// comparisonOperators maps the gt, gte, lt and lte rules to the operators
+// used in their error messages.
+var comparisonOperators = map[string]string{"gt": ">", "gte": ">=", "lt": "<", "lte": "<="}
+
+// compareRule reports whether value satisfies a gt, gte, lt or lte rule with
+// the given limit. Unlike min and max, gt and lt exclude the limit itself.
+func compareRule[T cmp.Ordered](name string, value, limit T) bool {
+	c := cmp.Compare(value, limit)
+	switch name {
+	case "gt":
+		return c > 0
+	case "gte":
+		return c >= 0
+	case "lt":
+		return c < 0
+	}
+	return c <= 0
+}
+
+// customRule applies a rule registered in Options.Validators. Errors returned
+// by the rule match ErrValidation.
+func customRule(custom map[string]ValidationFunc, r rule, value any) error {
+	validate, ok := custom[r.name]
+	if !ok {
+		return fmt.Errorf("unsupported validation rule: %s", r.name)
+	}
+	if err := validate(value, r.value); err != nil {
+		return &ValidationError{Rule: r.name, Limit: r.value, Value: value, err: err}
+	}
+	return nil
+}
+
+// isUUID reports whether s is a UUID in its canonical hyphenated form, e.g.
+// "123e4567-e89b-12d3-a456-426614174000", in either case.
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case i == 8 || i == 13 || i == 18 || i == 23:
+			if c != '-' {
+				return false
+			}
+		case !strings.ContainsRune("0123456789abcdefABCDEF", c):
+			return false
+		}
+	}
+	return true
+}
+
+// regexCache holds compiled regex rule patterns keyed by pattern string.
+var regexCache sync.Map
+
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexCache.Store(pattern, re)
+	return re, nil
+}
+
+// validateSliceField applies min/max/len rules to the number of elements in a slice.
+func validateSliceField(rules []rule, length int) error {
+	for _, r := range rules {
+		switch r.name {
+		case "required":
+			continue // Checked against the raw input before conversion
+		case "min", "max":
+			ruleValue, err := strconv.Atoi(r.value)
+			if err != nil {
+				return fmt.Errorf("invalid rule value: %v", err)
+			}
+			if r.name == "min" && length < ruleValue {
+				return validationErrorf(r, length, "slice length %d is less than min %d", length, ruleValue)
+			}
+			if r.name == "max" && length > ruleValue {
+				return validationErrorf(r, length, "slice length %d is greater than max %d", length, ruleValue)
+			}
+		case "len":
+			ruleValue, err := strconv.Atoi(r.value)
+			if err != nil {
+				return fmt.Errorf("invalid rule value: %v", err)
+			}
+			if length != ruleValue {
+				return validationErrorf(r, length, "slice length %d does not equal required %d", length, ruleValue)
+			}
+		default:
+			return fmt.Errorf("unsupported validation rule: %s", r.name)
+		}
+	}
+	return nil
+}
+
+// validateValue applies rules to a scalar value according to its kind. Kinds
+// without validators pass unchecked.
+func validateValue(rules []rule, value reflect.Value, custom map[string]ValidationFunc) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	switch value.Kind() {
+	case reflect.String:
+		return validateStringField(rules, value.String(), custom)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return validateIntField(rules, value.Int(), custom)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return validateUintField(rules, value.Uint(), custom)
+	case reflect.Float32, reflect.Float64:
+		return validateFloatField(rules, value.Float(), custom)
+	case reflect.Bool:
+		return validateBoolField(rules, value.Bool(), custom)
+	}
+	return nil
+}
@@ -0,0 +1,473 @@
+package structfill
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Strings
+type Account struct {
+	Username string `validate:"min=3,max=8"`
+}
+
+func TestFill_StringLengthValidation(t *testing.T) {
+	var account Account
+
+	err := Fill(&account, map[string]any{"username": "alice"})
+	assert.NoError(t, err)
+	assert.Equal(t, Account{Username: "alice"}, account)
+
+	err = Fill(&account, map[string]any{"username": "al"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "length 2 is less than min 3")
+
+	err = Fill(&account, map[string]any{"username": "alexander"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "length 9 is greater than max 8")
+}
+
+// Floats
+func TestFill_FloatRangeValidation(t *testing.T) {
+	var address Address
+
+	err := Fill(&address, map[string]any{"height": 1.75})
+	assert.NoError(t, err)
+	assert.Equal(t, 1.75, address.Height)
+
+	err = Fill(&address, map[string]any{"height": 1.2})
+	assert.Error(t, err)
+	assert.Equal(t, "Height: value 1.2 is less than min 1.5", err.Error())
+
+	err = Fill(&address, map[string]any{"height": "2.5"})
+	assert.Error(t, err)
+	assert.Equal(t, "Height: value 2.5 is greater than max 2", err.Error())
+}
+
+// Required
+type Signup struct {
+	Name  string `validate:"required"`
+	Age   int    `validate:"required,min=18"`
+	Email string `default:"none"`
+}
+
+func TestFill_Required(t *testing.T) {
+	var signup Signup
+
+	err := Fill(&signup, map[string]any{"name": "Alice", "age": 20})
+	assert.NoError(t, err)
+	assert.Equal(t, Signup{Name: "Alice", Age: 20, Email: "none"}, signup)
+
+	err = Fill(&signup, map[string]any{"age": 20})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "field Name is required")
+
+	err = Fill(&signup, map[string]any{"name": "", "age": 20})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "field Name is required")
+}
+
+func TestFill_RequiredComposesWithOtherRules(t *testing.T) {
+	var signup Signup
+
+	err := Fill(&signup, map[string]any{"name": "Alice", "age": 17})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "value 17 is less than min 18")
+}
+
+type Paint struct {
+	Color string `validate:"oneof=red green blue"`
+}
+
+func TestFill_StringOneOfValidation(t *testing.T) {
+	var paint Paint
+
+	err := Fill(&paint, map[string]any{"color": "green"})
+	assert.NoError(t, err)
+	assert.Equal(t, Paint{Color: "green"}, paint)
+
+	err = Fill(&paint, map[string]any{"color": "purple"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "value 'purple' is not one of [red green blue]")
+}
+
+type Slug struct {
+	Value string `validate:"min=3,regex=^[a-z0-9_]{1,8}$"`
+}
+
+func TestFill_StringRegexValidation(t *testing.T) {
+	var slug Slug
+
+	err := Fill(&slug, map[string]any{"value": "my_slug1"})
+	assert.NoError(t, err)
+	assert.Equal(t, Slug{Value: "my_slug1"}, slug)
+
+	err = Fill(&slug, map[string]any{"value": "Not-A-Slug"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "value 'Not-A-Slug' does not match pattern ^[a-z0-9_]{1,8}$")
+
+	// Earlier rules still apply
+	err = Fill(&slug, map[string]any{"value": "ab"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "length 2 is less than min 3")
+}
+
+// Slices
+type Playlist struct {
+	Songs []string `validate:"min=1,max=3"`
+}
+
+func TestFill_SliceLengthValidation(t *testing.T) {
+	var playlist Playlist
+
+	err := Fill(&playlist, map[string]any{"songs": []string{"a", "b"}})
+	assert.NoError(t, err)
+	assert.Equal(t, Playlist{Songs: []string{"a", "b"}}, playlist)
+
+	err = Fill(&playlist, map[string]any{"songs": []string{}})
+	assert.Error(t, err)
+	assert.Equal(t, "Songs: slice length 0 is less than min 1", err.Error())
+
+	err = Fill(&playlist, map[string]any{"songs": []string{"a", "b", "c", "d"}})
+	assert.Error(t, err)
+	assert.Equal(t, "Songs: slice length 4 is greater than max 3", err.Error())
+}
+
+type Census struct {
+	Ages []int `validate:"min=1,dive,min=0,max=120"`
+}
+
+func TestFill_SliceElementValidation(t *testing.T) {
+	var census Census
+
+	err := Fill(&census, map[string]any{"ages": []int{5, 42, 120}})
+	assert.NoError(t, err)
+	assert.Equal(t, Census{Ages: []int{5, 42, 120}}, census)
+
+	err = Fill(&census, map[string]any{"ages": []int{5, 130, 42}})
+	assert.Error(t, err)
+	assert.Equal(t, "Ages[1]: value 130 is greater than max 120", err.Error())
+
+	// Rules before dive still apply to the slice itself
+	err = Fill(&census, map[string]any{"ages": []int{}})
+	assert.Error(t, err)
+	assert.Equal(t, "Ages: slice length 0 is less than min 1", err.Error())
+}
+
+// Custom rules
+type Basket struct {
+	Eggs  int `validate:"min=0,multipleof=6"`
+	Price int `validate:"positive"`
+}
+
+var basketValidators = map[string]ValidationFunc{
+	"multipleof": func(value any, param string) error {
+		n, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			return err
+		}
+		if value.(int64)%n != 0 {
+			return fmt.Errorf("value %d is not a multiple of %d", value, n)
+		}
+		return nil
+	},
+	"positive": func(value any, _ string) error {
+		if value.(int64) <= 0 {
+			return fmt.Errorf("value %d is not positive", value)
+		}
+		return nil
+	},
+}
+
+func TestFillWithOptions_CustomValidators(t *testing.T) {
+	var basket Basket
+	opts := Options{Validators: basketValidators}
+
+	err := FillWithOptions(&basket, map[string]any{"eggs": 12, "price": 3}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, Basket{Eggs: 12, Price: 3}, basket)
+
+	err = FillWithOptions(&basket, map[string]any{"eggs": 13, "price": 3}, opts)
+	assert.Error(t, err)
+	assert.Equal(t, "Eggs: value 13 is not a multiple of 6", err.Error())
+	assert.True(t, errors.Is(err, ErrValidation))
+
+	err = FillWithOptions(&basket, map[string]any{"eggs": 6, "price": 0}, opts)
+	assert.Error(t, err)
+	assert.Equal(t, "Price: value 0 is not positive", err.Error())
+}
+
+func TestFill_UnregisteredRule(t *testing.T) {
+	var basket Basket
+
+	err := Fill(&basket, map[string]any{"eggs": 12})
+	assert.Error(t, err)
+	assert.Equal(t, "Eggs: unsupported validation rule: multipleof", err.Error())
+}
+
+// Bools
+type Consent struct {
+	Accepted  bool `validate:"eq=true"`
+	Marketing bool `default:""`
+}
+
+func TestFill_BoolEqValidation(t *testing.T) {
+	var consent Consent
+
+	err := Fill(&consent, map[string]any{"accepted": true})
+	assert.NoError(t, err)
+	assert.Equal(t, Consent{Accepted: true}, consent)
+
+	err = Fill(&consent, map[string]any{"accepted": "false"})
+	assert.Error(t, err)
+	assert.Equal(t, "Accepted: value false is not equal to true", err.Error())
+	assert.True(t, errors.Is(err, ErrValidation))
+}
+
+// Structured errors
+func TestFill_ValidationErrorDetails(t *testing.T) {
+	var person Employee
+
+	err := Fill(&person, map[string]any{"age": 70})
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, "Age", validationErr.Field)
+	assert.Equal(t, "max", validationErr.Rule)
+	assert.Equal(t, "65", validationErr.Limit)
+	assert.Equal(t, int64(70), validationErr.Value)
+	assert.Equal(t, "value 70 is greater than max 65", validationErr.Error())
+
+	err = Fill(&person, map[string]any{"address": map[string]any{"height": 2.5}})
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, "Height", validationErr.Field)
+	assert.Equal(t, "max", validationErr.Rule)
+	assert.Equal(t, "2.0", validationErr.Limit)
+	assert.Equal(t, 2.5, validationErr.Value)
+}
+
+func TestFill_ValidationErrorDetailsForElements(t *testing.T) {
+	var census Census
+	var validationErr *ValidationError
+
+	err := Fill(&census, map[string]any{"ages": []int{5, 130}})
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, "Ages", validationErr.Field)
+	assert.Equal(t, "max", validationErr.Rule)
+	assert.Equal(t, "120", validationErr.Limit)
+	assert.Equal(t, int64(130), validationErr.Value)
+
+	var signup Signup
+	err = Fill(&signup, map[string]any{"age": 20})
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, "Name", validationErr.Field)
+	assert.Equal(t, "required", validationErr.Rule)
+	assert.Equal(t, "", validationErr.Limit)
+}
+
+// Trimming
+type Handle struct {
+	Name  string `fill:",trim" validate:"min=3,max=8"`
+	Label string `validate:"max=5"`
+}
+
+func TestFill_TrimTagBeforeValidation(t *testing.T) {
+	var handle Handle
+
+	err := Fill(&handle, map[string]any{"name": "  alice   ", "label": " x "})
+	assert.NoError(t, err)
+	assert.Equal(t, Handle{Name: "alice", Label: " x "}, handle)
+
+	err = Fill(&handle, map[string]any{"name": "  al  "})
+	assert.Error(t, err)
+	assert.Equal(t, "Name: length 2 is less than min 3", err.Error())
+}
+
+func TestFillWithOptions_TrimSpace(t *testing.T) {
+	var handle Handle
+
+	err := FillWithOptions(&handle, map[string]any{"label": "   short   "}, Options{TrimSpace: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "short", handle.Label)
+}
+
+// Exact lengths
+type Contact struct {
+	Phone string   `validate:"required,len=11,regex=^[0-9]+$"`
+	Tags  []string `validate:"len=2"`
+}
+
+func TestFill_LenValidation(t *testing.T) {
+	var contact Contact
+
+	err := Fill(&contact, map[string]any{"phone": "07700900123", "tags": []string{"a", "b"}})
+	assert.NoError(t, err)
+	assert.Equal(t, Contact{Phone: "07700900123", Tags: []string{"a", "b"}}, contact)
+
+	err = Fill(&contact, map[string]any{"phone": "0770090012"})
+	assert.Error(t, err)
+	assert.Equal(t, "Phone: length 10 does not equal required 11", err.Error())
+
+	err = Fill(&contact, map[string]any{"phone": "0770090012a"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "does not match pattern")
+
+	err = Fill(&contact, map[string]any{"phone": "07700900123", "tags": []string{"a"}})
+	assert.Error(t, err)
+	assert.Equal(t, "Tags: slice length 1 does not equal required 2", err.Error())
+}
+
+// Map values
+type Scores struct {
+	Points map[string]int     `validate:"min=0,max=100"`
+	Grades map[string]string  `validate:"dive,oneof=A B C"`
+	Ratios map[string]float64 `validate:"max=1"`
+}
+
+func TestFill_MapValueValidation(t *testing.T) {
+	var scores Scores
+
+	err := Fill(&scores, map[string]any{
+		"points": map[string]any{"alice": 90, "bob": 0},
+		"grades": map[string]any{"alice": "A"},
+		"ratios": map[string]any{"alice": 0.5},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, Scores{
+		Points: map[string]int{"alice": 90, "bob": 0},
+		Grades: map[string]string{"alice": "A"},
+		Ratios: map[string]float64{"alice": 0.5},
+	}, scores)
+
+	err = Fill(&scores, map[string]any{"points": map[string]any{"carol": -5}})
+	assert.Error(t, err)
+	assert.Equal(t, "Points[carol]: value -5 is less than min 0", err.Error())
+	assert.ErrorIs(t, err, ErrValidation)
+
+	err = Fill(&scores, map[string]any{"grades": map[string]any{"bob": "F"}})
+	assert.Error(t, err)
+	assert.Equal(t, "Grades[bob]: value 'F' is not one of [A B C]", err.Error())
+
+	err = Fill(&scores, map[string]any{"ratios": map[string]any{"bob": 1.5}})
+	assert.Error(t, err)
+	assert.Equal(t, "Ratios[bob]: value 1.5 is greater than max 1", err.Error())
+}
+
+// Required containers
+type Shipment struct {
+	Address Address           `validate:"required"`
+	Items   []string          `validate:"required"`
+	Labels  map[string]string `validate:"required"`
+	Notes   []string
+}
+
+func TestFill_RequiredNestedStructsAndSlices(t *testing.T) {
+	var shipment Shipment
+	inputMap := map[string]any{
+		"address": map[string]any{"city": "Springfield"},
+		"items":   []string{"box"},
+		"labels":  map[string]any{"fragile": "yes"},
+	}
+
+	err := Fill(&shipment, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, Shipment{
+		Address: Address{Street: "Main St", City: "Springfield", Height: 1.8},
+		Items:   []string{"box"},
+		Labels:  map[string]string{"fragile": "yes"},
+	}, shipment)
+
+	// A nested struct is not default-initialized when it is required
+	err = Fill(&shipment, map[string]any{"items": []string{"box"}, "labels": map[string]any{}})
+	assert.Error(t, err)
+	assert.Equal(t, "Address: field Address is required", err.Error())
+
+	err = Fill(&shipment, map[string]any{"address": map[string]any{}, "labels": map[string]any{}})
+	assert.Error(t, err)
+	assert.Equal(t, "Items: field Items is required", err.Error())
+
+	err = Fill(&shipment, map[string]any{"address": map[string]any{}, "items": []string{"box"}})
+	assert.Error(t, err)
+	assert.Equal(t, "Labels: field Labels is required", err.Error())
+}
+
+// Formats
+type Registration struct {
+	Email    string `validate:"email"`
+	Homepage string `validate:"url"`
+	Token    string `validate:"uuid"`
+}
+
+func TestFill_FormatValidation(t *testing.T) {
+	var registration Registration
+	inputMap := map[string]any{
+		"email":    "ann@example.com",
+		"homepage": "https://example.com/ann",
+		"token":    "123e4567-E89B-12d3-a456-426614174000",
+	}
+
+	err := Fill(&registration, inputMap)
+	assert.NoError(t, err)
+	assert.Equal(t, Registration{Email: "ann@example.com", Homepage: "https://example.com/ann", Token: "123e4567-E89B-12d3-a456-426614174000"}, registration)
+}
+
+func TestFill_FormatValidationInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		key, input, want string
+	}{
+		{"email", "ann@", "Email: value 'ann@' is not a valid email address"},
+		{"email", "Ann <ann@example.com>", "Email: value 'Ann <ann@example.com>' is not a valid email address"},
+		{"homepage", "example.com", "Homepage: value 'example.com' is not a valid URL"},
+		{"homepage", "https://", "Homepage: value 'https://' is not a valid URL"},
+		{"token", "123e4567e89b12d3a456426614174000", "Token: value '123e4567e89b12d3a456426614174000' is not a valid UUID"},
+		{"token", "123e4567-e89b-12d3-a456-42661417400g", "Token: value '123e4567-e89b-12d3-a456-42661417400g' is not a valid UUID"},
+	} {
+		var registration Registration
+
+		err := Fill(&registration, map[string]any{tc.key: tc.input})
+		assert.Error(t, err, tc.input)
+		assert.Equal(t, tc.want, err.Error())
+		assert.ErrorIs(t, err, ErrValidation)
+	}
+}
+
+// Comparisons
+type Thresholds struct {
+	Workers int     `validate:"gt=5"`
+	Queue   uint    `validate:"gte=5"`
+	Ratio   float64 `validate:"gt=0,lt=1"`
+	Budget  int     `validate:"lte=5"`
+}
+
+func TestFill_ComparisonValidation(t *testing.T) {
+	var thresholds Thresholds
+
+	err := Fill(&thresholds, map[string]any{"workers": 6, "queue": 5, "ratio": 0.5, "budget": 5})
+	assert.NoError(t, err)
+	assert.Equal(t, Thresholds{Workers: 6, Queue: 5, Ratio: 0.5, Budget: 5}, thresholds)
+}
+
+func TestFill_ComparisonValidationBoundaries(t *testing.T) {
+	for _, tc := range []struct {
+		key   string
+		value any
+		want  string
+	}{
+		{"workers", 5, "Workers: value 5 must be > 5"},
+		{"queue", 4, "Queue: value 4 must be >= 5"},
+		{"ratio", 0.0, "Ratio: value 0 must be > 0"},
+		{"ratio", 1.0, "Ratio: value 1 must be < 1"},
+		{"budget", 6, "Budget: value 6 must be <= 5"},
+	} {
+		var thresholds Thresholds
+
+		err := Fill(&thresholds, map[string]any{tc.key: tc.value})
+		assert.Error(t, err, tc.want)
+		assert.Equal(t, tc.want, err.Error())
+
+		var validationErr *ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+	}
+}